@@ -14,7 +14,8 @@ import (
 	"os"
 	"os/exec"
 	"regexp"
-	"strings"
+	"strconv"
+	"sync"
 	"text/template"
 
 	"github.com/alecthomas/kingpin"
@@ -54,21 +55,23 @@ type Forj struct {
 
 	// cli commands modules
 	secrets   secrets
+	flowCmd   flowCmd
 	workspace forjjWorkspace.Workspace
 
 	contextAction string // Context action defined in ParseContext.
 	// Can be create/update or maintain. But it can be any others, like secrets...
 
-	actionDispatch map[string]func(string)
+	actionDispatch map[string]func(string) error // Actions return the command error to the main error handler.
+	parallel       map[string]*int                // --parallel value, by action.
 
-	CurrentPluginDriver *drivers.Driver // Driver executing
-	InfraPluginDriver   *drivers.Driver // Driver used by upstream
+	InfraPluginDriver *drivers.Driver // Driver used by upstream
+	driversLock       *sync.Mutex     // Set when drivers are executed by do_drivers_run.
 
 	// Forjj Core values, saved at create time, updated at update time. maintain should save also.
 
 	InternalForjData     map[string]string
 	creds_file           *string // Credential file
-	forjfile_tmpl_path   string
+	forjfile_tmpl_paths  []string // Forjfile models paths given to create/validate.
 	Branch               string     // Update feature branch name
 	ContribRepoURIs      []*url.URL // URL to github raw files for plugin files.
 	RepotemplateRepo_uri *url.URL   // URL to github raw files for RepoTemplates.
@@ -93,6 +96,12 @@ type Forj struct {
 	i repository.GitRepoStruct // Infra Repository management.
 
 	deployContext forjDeployContext
+	deployments   []*forjfile.DeploymentStruct // Deployments to update/maintain with --all or --type.
+
+	output forjOutput // Command result, reported with `--output json`.
+
+	transaction *forjTransaction // Current create/update transaction. nil otherwise.
+	stateKey    pluginStateKey   // Workspace key used by plugin states.
 }
 
 /*const (
@@ -109,6 +118,11 @@ const (
 	ren_act     string = "rename"
 	list_act    string = "list"
 	maint_act   string = "maintain"
+	plan_act    string = "plan"
+	explain_act string = "explain"
+	promote_act string = "promote"
+	schema_act  string = "schema"
+	flow_act    string = "flow"
 	common_acts string = "common" // Refer to all other actions
 )
 
@@ -135,6 +149,26 @@ const (
 	ssh_dir_f     = "ssh-dir"
 	no_maintain_f = "no-maintain"
 	message_f     = "message"
+	output_f      = "output" // Output format. text or json.
+	parallel_f    = "parallel" // Maximum number of driver instances to run at the same time.
+	// plan flags
+	planMaintainF = "maintain"
+	// explain args
+	explainPathArg = "key"
+	// maintain flags
+	maintainResumeF = "resume"
+	// update/maintain flags
+	deploymentsAllF      = "all"
+	deploymentsTypeF     = "type"
+	deploymentsFailFastF = "fail-fast"
+	// add/change/remove/rename flags
+	objectUpdateF = "update"
+	// validate flags
+	validateAllDeploymentsF = "all-deployments"
+	// promote args and flags
+	promoteFromArg = "from"
+	promoteOnlyF   = "only"
+	promoteDryRunF = "dry-run"
 )
 
 const (
@@ -207,10 +241,12 @@ func (a *Forj) init() {
 	opts_infra_path := cli.Opts().Envar("FORJJ_INFRA").Short('W')
 	opts_forjfile := cli.Opts().Short('F').Default(".")
 	opts_message := cli.Opts().Short('m')
+	opts_output := cli.Opts().Envar("FORJJ_OUTPUT").Default(outputText)
 
 	a.app = kingpin.New(os.Args[0], forjj_help).UsageTemplate(DefaultUsageTemplate)
 
 	a.secrets.init(a.app)
+	a.flowCmd.init(a.app)
 	a.workspace.Init(a.app, &a.w, a.cli.IsParsePhase, func(context *forjjWorkspace.Context, cmd *kingpin.CmdClause) {
 		// Define Common flags required by ParseContext
 
@@ -236,7 +272,7 @@ func (a *Forj) init() {
 		version = "forjj V" + VERSION
 	}
 
-	fmt.Printf("branch %s, build_date %s, build_commit %s, build_tag %s.\n",
+	fmt.Fprintf(os.Stderr, "branch %s, build_date %s, build_commit %s, build_tag %s.\n",
 		build_branch, build_date, build_commit, build_tag)
 
 	if build_branch != "master" {
@@ -259,14 +295,25 @@ func (a *Forj) init() {
 	a.cli.AddAppFlag(cli.String, cred_f, forjj_creds_help, opts_creds_file)
 	a.cli.AddAppFlag(cli.String, debug_instance_f, "List of plugin instances in debug mode, comma separated.",
 		nil)
+	a.cli.AddAppFlag(cli.String, output_f, forjj_output_help, opts_output)
 
-	a.actionDispatch = make(map[string]func(string))
+	a.actionDispatch = make(map[string]func(string) error)
 	a.actionDispatch[cr_act] = a.createAction
 	a.actionDispatch[upd_act] = a.updateAction
 	a.actionDispatch[maint_act] = a.maintainAction
 	a.actionDispatch[val_act] = a.validateAction
+	a.actionDispatch[plan_act] = a.planAction
+	a.actionDispatch[explain_act] = a.explainAction
+	a.actionDispatch[promote_act] = a.promoteAction
+	a.actionDispatch[schema_act] = a.schemaAction
 	a.actionDispatch["secrets"] = a.secrets.action
-	a.actionDispatch["workspace"] = a.workspace.Action
+	a.actionDispatch[flow_act] = a.flowCmd.action
+	a.actionDispatch[list_act] = a.listAction
+	a.actionDispatch[add_act] = a.objectAction
+	a.actionDispatch[chg_act] = a.objectAction
+	a.actionDispatch[rem_act] = a.objectAction
+	a.actionDispatch[ren_act] = a.objectAction
+	a.actionDispatch["workspace"] = a.workspaceAction
 
 	a.drivers = make(map[string]*drivers.Driver)
 	a.plugins = goforjj.NewPlugins()
@@ -280,6 +327,10 @@ func (a *Forj) init() {
 	a.cli.NewActions(upd_act, update_action_help, "Update %s.", true)
 	a.cli.NewActions(maint_act, maintain_action_help, "Maintain %s.", true)
 	a.cli.NewActions(val_act, val_act_help, "", true)
+	a.cli.NewActions(plan_act, plan_action_help, "", true)
+	a.cli.NewActions(explain_act, explain_action_help, "", true)
+	a.cli.NewActions(promote_act, promote_action_help, "", true)
+	a.cli.NewActions(schema_act, schema_action_help, "", true)
 	a.cli.NewActions(add_act, add_action_help, "Add %s to your software factory.", false)
 	a.cli.NewActions(chg_act, update_action_help, "Update %s of your software factory.", false)
 	a.cli.NewActions(rem_act, remove_action_help, "Remove/disable %s from your software factory.", false)
@@ -356,13 +407,16 @@ func (a *Forj) init() {
 		AddKey(cli.String, "name", app_name_help, "#w", nil).
 		AddField(cli.String, "type", app_type_help, "#w", nil).
 		AddField(cli.String, "driver", app_driver_help, "#w", nil).
-		DefineActions(add_act, chg_act, rem_act, list_act).
+		AddField(cli.String, "new_name", new_app_name_help, "#w", nil).
+		DefineActions(add_act, chg_act, rem_act, ren_act, list_act).
 		OnActions(add_act).
 		AddArg("type", opts_required).
 		AddArg("driver", opts_required).
 		AddArg("name", nil).
-		OnActions(chg_act, rem_act).
+		OnActions(chg_act, rem_act, ren_act).
 		AddArg("name", opts_required).
+		OnActions(ren_act).
+		AddArg("new_name", opts_required).
 		OnActions(list_act).
 		AddFlag("type", nil).
 		AddFlag("driver", nil).
@@ -429,6 +483,7 @@ func (a *Forj) init() {
 		AddFlag(cli.String, ssh_dir_f, create_ssh_dir_help, nil).
 		// TODO: Support for a different Forjfile name. (using forjfile_name_f constant)
 		AddFlag(cli.String, forjfile_path_f, create_forjfile_help, opts_forjfile).
+		AddFlag(cli.String, deployToArg, createDeployToHelp, nil).
 		AddFlag(cli.Bool, no_maintain_f, create_no_maintain_help, nil) == nil {
 		log.Printf("action create: %s", a.cli.Error())
 	}
@@ -437,7 +492,9 @@ func (a *Forj) init() {
 		// Add Update workspace flags to Create action, not prefixed.
 		// ex: forjj create --docker-exe-path ...
 		AddActionFlagsFromObjectAction(workspace, chg_act).
-		AddFlag(cli.String, forjfile_path_f, create_forjfile_help, opts_forjfile) == nil {
+		AddFlag(cli.String, forjfile_path_f, create_forjfile_help, opts_forjfile).
+		AddFlag(cli.String, deployToArg, validateDeployToHelp, nil).
+		AddFlag(cli.Bool, validateAllDeploymentsF, validateAllHelp, nil) == nil {
 		log.Printf("action create: %s", a.cli.Error())
 	}
 
@@ -447,6 +504,9 @@ func (a *Forj) init() {
 		// ex: forjj update --docker-exe-path ...
 		AddActionFlagsFromObjectAction(workspace, chg_act).
 		AddArg(cli.String, deployToArg, updateDeployToHelp, nil).
+		AddFlag(cli.Bool, deploymentsAllF, updateAllHelp, nil).
+		AddFlag(cli.String, deploymentsTypeF, updateTypeHelp, nil).
+		AddFlag(cli.Bool, deploymentsFailFastF, deploymentsFailFastHelp, nil).
 		AddFlag(cli.Bool, "deploy-publish", updateDeployPublishHelp, nil).
 		AddFlag(cli.String, "ssh-dir", create_ssh_dir_help, nil) == nil {
 		log.Printf("action update: %s", a.cli.Error())
@@ -456,11 +516,63 @@ func (a *Forj) init() {
 	if a.cli.OnActions(maint_act).
 		AddActionFlagsFromObjectAction(workspace, chg_act).
 		AddActionFlagFromObjectAction(infra, chg_act, infra_path_f).
-		AddArg(cli.String, deployToArg, maintainDeployToHelp, opts_required).
-		AddFlag(cli.String, "file", maintain_option_file, nil) == nil {
+		AddArg(cli.String, deployToArg, maintainDeployToHelp, nil).
+		AddFlag(cli.Bool, deploymentsAllF, maintainAllHelp, nil).
+		AddFlag(cli.String, deploymentsTypeF, maintainTypeHelp, nil).
+		AddFlag(cli.Bool, deploymentsFailFastF, deploymentsFailFastHelp, nil).
+		AddFlag(cli.String, "file", maintain_option_file, nil).
+		AddFlag(cli.Bool, maintainResumeF, maintainResumeHelp, nil) == nil {
 		log.Printf("action maintain: %s", a.cli.Error())
 	}
 
+	// --parallel is an integer. The cli module supports only strings and bools, so it is a kingpin flag.
+	a.parallel = make(map[string]*int)
+	for _, action := range []string{cr_act, upd_act, maint_act} {
+		if cmd := a.app.GetCommand(action); cmd != nil {
+			a.parallel[action] = cmd.Flag(parallel_f, parallelHelp).Envar("FORJJ_PARALLEL").Default(strconv.Itoa(defaultParallel)).Int()
+		}
+	}
+
+	// Forjfile objects actions. The Forjfile update can be followed by an update of the deployment.
+	// ex: forjj add repo myrepo --update
+	if a.cli.OnActions(add_act, chg_act, rem_act, ren_act).
+		AddFlag(cli.Bool, objectUpdateF, objectUpdateHelp, nil) == nil {
+		log.Printf("objects actions: %s", a.cli.Error())
+	}
+
+	// Plan. Report what update/maintain would send to plugins.
+	if a.cli.OnActions(plan_act).
+		AddActionFlagsFromObjectAction(workspace, chg_act).
+		AddArg(cli.String, deployToArg, planDeployToHelp, opts_required).
+		AddFlag(cli.Bool, planMaintainF, planMaintainHelp, nil) == nil {
+		log.Printf("action plan: %s", a.cli.Error())
+	}
+
+	// Explain. Report where Forjfile values come from.
+	if a.cli.OnActions(explain_act).
+		AddActionFlagsFromObjectAction(workspace, chg_act).
+		AddArg(cli.String, deployToArg, explainDeployToHelp, opts_required).
+		AddArg(cli.String, explainPathArg, explainPathHelp, opts_required) == nil {
+		log.Printf("action explain: %s", a.cli.Error())
+	}
+
+	// Promote. Copy a deployment Forjfile values to another deployment.
+	// The target deployment is the deployment environment used by forjj.
+	if a.cli.OnActions(promote_act).
+		AddActionFlagsFromObjectAction(workspace, chg_act).
+		AddArg(cli.String, promoteFromArg, promoteFromHelp, opts_required).
+		AddArg(cli.String, deployToArg, promoteDeployToHelp, opts_required).
+		AddFlag(cli.String, promoteOnlyF, promoteOnlyHelp, nil).
+		AddFlag(cli.Bool, promoteDryRunF, promoteDryRunHelp, nil) == nil {
+		log.Printf("action promote: %s", a.cli.Error())
+	}
+
+	// Schema. JSON Schema of the Forjfile, with plugins keys.
+	if a.cli.OnActions(schema_act).
+		AddActionFlagsFromObjectAction(workspace, chg_act) == nil {
+		log.Printf("action schema: %s", a.cli.Error())
+	}
+
 	_, err := exec.LookPath("git")
 	kingpin.FatalIfError(err, "Unable to find 'git' command. Ensure it available in your PATH and retry.\n")
 
@@ -470,38 +582,44 @@ func (a *Forj) init() {
 	a.AddMap(infra_upstream_f, infra, "", infra_upstream_f, infra, "", "apps:upstream")
 	a.AddMap(deployToArg, "_app", "forjj", deployToArg, "settings", "default", "dev-deploy")
 	a.AddMapFunc("secrets", deployToArg, a.secrets.context.GetStringValue)
+	a.AddMapFunc(flow_act, deployToArg, a.flowCmd.GetStringValue)
 
 	a.AddMap(infra_path_f, workspace, "", infra_path_f, workspace, "", infra_path_f)
 	a.AddMapFunc("secrets", infra_path_f, a.secrets.GetStringValue)
 	a.AddMapFunc("workspace", infra_path_f, a.workspace.GetStringValue)
+	a.AddMapFunc(flow_act, infra_path_f, a.flowCmd.GetStringValue)
 
 	a.AddMap("contribs-repo", workspace, "", "contribs-repo", "", "", forjfile.ContribRepoPathField)
 	a.AddMapFunc("secrets", "contribs-repo", a.secrets.GetStringValue)
 	a.AddMapFunc("workspace", "contribs-repo", a.workspace.GetStringValue)
+	a.AddMapFunc(flow_act, "contribs-repo", a.flowCmd.GetStringValue)
 
 	a.AddMap("flows-repo", workspace, "", "flows-repo", "", "", "flow-repo-path")
 	a.AddMapFunc("secrets", "flows-repo", a.secrets.GetStringValue)
 	a.AddMapFunc("workspace", "flows-repo", a.workspace.GetStringValue)
+	a.AddMapFunc(flow_act, "flows-repo", a.flowCmd.GetStringValue)
 
 	a.AddMap("repotemplates-repo", workspace, "", "repotemplates-repo", "", "", "repotemplate-repo-path")
 	a.AddMapFunc("secrets", "repotemplates-repo", a.secrets.GetStringValue)
 	a.AddMapFunc("workspace", "repotemplates-repo", a.workspace.GetStringValue)
+	a.AddMapFunc(flow_act, "repotemplates-repo", a.flowCmd.GetStringValue)
 	// TODO: Add git-remote cli mapping
 }
 
-// LoadInternalData()
-func (a *Forj) LoadInternalData() {
+// LoadInternalData loads forjj internal parameters for the driver given.
+func (a *Forj) LoadInternalData(d *drivers.Driver) {
 	a.InternalForjData = make(map[string]string)
 	ldata := []string{"organization", "infra", "infra-upstream", "instance-name", "source-mount", "workspace-mount", "deploy-mount", "username", "secrets"}
 	for _, param := range ldata {
-		a.InternalForjData[param] = a.getInternalData(param)
+		a.InternalForjData[param] = a.getInternalData(d, param)
 	}
 }
 
 // GetInternalData
 //
 // Provide value for some forjj internal parameters. Used by InitializeDriversFlag to provide values to plugins as they requested it.
-func (a *Forj) getInternalData(param string) (result string) {
+// Plugin parameters are given by the driver `d`.
+func (a *Forj) getInternalData(d *drivers.Driver, param string) (result string) {
 	switch param {
 	case "organization":
 		result = a.w.GetString(param)
@@ -518,26 +636,26 @@ func (a *Forj) getInternalData(param string) (result string) {
 			}
 		}
 	case "instance-name":
-		if a.CurrentPluginDriver != nil {
-			result = a.CurrentPluginDriver.InstanceName
+		if d != nil {
+			result = d.InstanceName
 		} else {
 			gotrace.Trace("Warning. instance_name requested outside plugin context.")
 		}
 	case "source-mount": // where the plugin has source source mounted in the container
-		if a.CurrentPluginDriver != nil {
-			result = a.CurrentPluginDriver.Plugin.SourceMount
+		if d != nil {
+			result = d.Plugin.SourceMount
 		} else {
 			gotrace.Trace("Warning. source-mount requested outside plugin context.")
 		}
 	case "workspace-mount": // where the plugin has source workspace mounted to the container from caller
-		if a.CurrentPluginDriver != nil {
-			result = a.CurrentPluginDriver.Plugin.WorkspaceMount
+		if d != nil {
+			result = d.Plugin.WorkspaceMount
 		} else {
 			gotrace.Trace("Warning. workspace-mount requested outside plugin context.")
 		}
 	case "deploy-mount": // where the plugin has soure deployment mounted to the container
-		if a.CurrentPluginDriver != nil {
-			result = a.CurrentPluginDriver.Plugin.DestMount
+		if d != nil {
+			result = d.Plugin.DestMount
 		} else {
 			gotrace.Trace("Warning. deploy-mount requested outside plugin context.")
 		}
@@ -592,9 +710,7 @@ func (a *Forj) GetDriversActionsParameter(d *drivers.Driver, flag_name, action s
 			// Initialized defaults value from templates
 			var doc bytes.Buffer
 
-			if t, err := template.New("forj-data").Funcs(template.FuncMap{
-				"ToLower": strings.ToLower,
-			}).Parse(v); err != nil {
+			if t, err := template.New("forj-data").Funcs(a.f.InMemForjfile().TemplateFuncs()).Parse(v); err != nil {
 				gotrace.Trace("Unable to interpret Parameter '%s' value '%s'. %s", parameter_name, v, err)
 				return "", false
 			} else {
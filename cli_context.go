@@ -42,10 +42,17 @@ func (a *Forj) ParseContext(c *cli.ForjCli, _ interface{}) (error, bool) {
 		return nil, false
 	}
 
+	if v, err := a.cli.GetAppStringValue(output_f); err == nil {
+		if err := a.output.setFormat(v, a.contextAction); err != nil {
+			return err, false
+		}
+	}
+
 	// Transmit context to additionnal cli commands not managed by forjj_module/cli
 	// This ParseContext works on some flags that all other command outside forjj_module/cli
 	// have to define, like FORJJ_INFRA (--infra-path)
 	a.secrets.DefineContext(c.GetParseContext())
+	a.flowCmd.DefineContext(c.GetParseContext())
 	a.workspace.DefineContext(c.GetParseContext())
 
 	if a.contextAction == cr_act || a.contextAction == val_act {
@@ -66,7 +73,7 @@ func (a *Forj) ParseContext(c *cli.ForjCli, _ interface{}) (error, bool) {
 	a.w.Load()
 
 	// Read definition file from repo.
-	is_valid_action := (utils.InStringList(a.contextAction, val_act, cr_act, upd_act, maint_act, add_act, rem_act, ren_act, chg_act, list_act) != "")
+	is_valid_action := (utils.InStringList(a.contextAction, val_act, cr_act, upd_act, maint_act, plan_act, explain_act, promote_act, schema_act, flow_act, add_act, rem_act, ren_act, chg_act, list_act) != "")
 	need_to_create := (a.contextAction == cr_act)
 	need_to_update := (a.contextAction == upd_act)
 	need_to_validate := (a.contextAction == val_act)
@@ -77,7 +84,7 @@ func (a *Forj) ParseContext(c *cli.ForjCli, _ interface{}) (error, bool) {
 
 	// Load Forjfile from infra repo, if found.
 	if err := a.LoadForge(); err != nil {
-		if utils.InStringList(a.contextAction, upd_act, maint_act, add_act, rem_act, ren_act, chg_act, list_act) != "" {
+		if utils.InStringList(a.contextAction, upd_act, maint_act, plan_act, explain_act, promote_act, schema_act, flow_act, add_act, rem_act, ren_act, chg_act, list_act) != "" {
 			a.w.SetError(fmt.Errorf("Forjfile not loaded. %s", err))
 			return nil, false
 		}
@@ -89,6 +96,21 @@ func (a *Forj) ParseContext(c *cli.ForjCli, _ interface{}) (error, bool) {
 		return nil, false
 	}
 
+	// With --all or --type, update/maintain are run on each deployment selected. See deployments_run.go
+	// The context is defined from the first one.
+	if utils.InStringList(a.contextAction, upd_act, maint_act) != "" {
+		if deploys, found, err := a.selectDeployments(); err != nil {
+			a.w.SetError(err)
+			return nil, false
+		} else if found {
+			a.deployments = deploys
+			a.f.SetDeployment(deploys[0].Name())
+		} else if v, _, _, _ := a.cli.GetStringValue("_app", "forjj", deployToArg); a.contextAction == maint_act && v == "" {
+			a.w.SetError(fmt.Errorf("Missing the deployment environment to maintain. Give one, or use --%s or --%s", deploymentsAllF, deploymentsTypeF))
+			return nil, false
+		}
+	}
+
 	// By default, with `forjj update`, the deploy source code generated by forjj are generated in a repo beside the infra cloned repo.
 	// This is the default use case from Developer side. And Only DEV type can manage the beside infra repository.
 	// TEST/PRO always uses the internal workspace.
@@ -117,13 +139,15 @@ func (a *Forj) ParseContext(c *cli.ForjCli, _ interface{}) (error, bool) {
 
 	// Define the current deployment in create mode.
 	if need_to_create || need_to_validate {
-		// TODO: Be able to choose another deployment than the PRO one in create phase.
-
-		// Determine the default PRO deployment
-		if v, err := a.f.GetDeploymentPROType(); err != nil {
+		// The PRO deployment, unless another one is given with --deploy-to
+		deployTo := ""
+		if v, found, isDefault, _ := a.cli.GetStringValue("_app", "forjj", deployToArg); found && !isDefault {
+			deployTo = v
+		}
+		if v, err := a.f.GetDeploymentToCreate(deployTo); err != nil {
 			return err, false
 		} else {
-			gotrace.Info("Using production deployment: '%s'.", v.Name())
+			gotrace.Info("Using %s deployment: '%s'.", v.Type, v.Name())
 			a.f.SetDeployment(v.Name())
 			a.d = &v.DeploymentCoreStruct
 		}
@@ -141,10 +165,18 @@ func (a *Forj) ParseContext(c *cli.ForjCli, _ interface{}) (error, bool) {
 
 	}
 
-	if a.f.GetDeployment() == "global" && (utils.InStringList(a.contextAction, val_act, cr_act, upd_act, maint_act) != "") {
+	if a.f.GetDeployment() == "global" && (utils.InStringList(a.contextAction, val_act, cr_act, upd_act, maint_act, plan_act, explain_act, promote_act, flow_act) != "") {
 		return fmt.Errorf("'global' is not a valid deployment environment"), false
 	}
 
+	// With --all or --type, each deployment run checks its own deployment type rules.
+	if a.deployments == nil {
+		if err := a.checkDeploymentTypeRules(deployPublish); err != nil {
+			a.w.SetError(err)
+			return nil, false
+		}
+	}
+
 	// Build in memory representation from source files loaded.
 	if err := a.f.BuildForjfileInMem(); err != nil {
 		return err, false
@@ -429,5 +461,5 @@ func (a *Forj) setFromURLFlag(flag string, Set func(string, string) bool) (u *ur
 		return
 	}
 
-	return nil, fmt.Errorf("Unable to define '%s'. Value not found in cli or workspace data.")
+	return nil, fmt.Errorf("Unable to define '%s'. Value not found in cli or workspace data.", flag)
 }
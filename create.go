@@ -14,9 +14,9 @@ import (
 	"github.com/forj-oss/forjj-modules/trace"
 )
 
-func (a *Forj) createAction(string) {
+func (a *Forj) createAction(string) error {
 	if err := a.Create(); err != nil {
-		log.Fatalf("Forjj create issue. %s.", err)
+		return fmt.Errorf("Forjj create issue. %s.", err)
 	}
 	log.Print("===========================================")
 	if !*a.no_maintain {
@@ -24,12 +24,13 @@ func (a *Forj) createAction(string) {
 		// This will implement the flow for the infra-repo as well.
 		a.from_create = true
 		if err := a.do_maintain(); err != nil {
-			log.Fatalf("Forjj create instance (maintain) issue. %s", err)
+			return fmt.Errorf("Forjj create instance (maintain) issue. %s", err)
 		}
 	} else {
 		log.Print("Source codes are in place. Now, Please review commits, push and start instantiating your DevOps Environment services with 'forjj maintain' ...")
 	}
 	println("FORJJ - create ", a.w.GetString("organization"), " DONE") // , cmd.ProcessState.Sys().WaitStatus)
+	return nil
 }
 
 //  initial_commit is called by infra.Create to create the initial commit with any needed files.
@@ -219,17 +220,21 @@ func (a *Forj) createDeployment(deploy string) error {
 		}
 	}()
 
-	instances := a.define_drivers_execution_order()
+	// Record infra and deploy repositories starting points, to restore them if a driver fails.
+	transaction, err := a.beginTransaction()
+	if err != nil {
+		return err
+	}
 
-	// Loop on drivers requested like github or jenkins
-	for _, instance := range instances {
+	// Run drivers requested like github or jenkins, following their dependencies.
+	if err := a.do_drivers_run(func(instance string) error {
 		d := a.drivers[instance]
 		if err, aborted := a.do_driver_task("create", instance); err != nil {
 			if !aborted {
 				return fmt.Errorf("Failed to create '%s' source files. %s", instance, err)
 			}
 			log.Printf("Warning. %s", err)
-			continue
+			return nil
 		}
 
 		if d.HasNoFiles() {
@@ -240,16 +245,20 @@ func (a *Forj) createDeployment(deploy string) error {
 		if err := a.do_driver_add(d); err != nil {
 			return fmt.Errorf("Failed to Add '%s' source files. %s", instance, err)
 		}
+		return nil
+	}); err != nil {
+		return transaction.rollback(err)
 	}
 
 	commitMsg := fmt.Sprintf("Forge '%s' created.", a.w.GetString("organization"))
 	if err := git.Commit(commitMsg, true); err != nil {
-		return fmt.Errorf("Failed to commit source files. %s", err)
+		return transaction.rollback(fmt.Errorf("Failed to commit source files. %s", err))
 	}
 
 	if err := a.d.GitCommit(commitMsg); err != nil {
-		return fmt.Errorf("Failed to commit deploy files. %s", err)
+		return transaction.rollback(fmt.Errorf("Failed to commit deploy files. %s", err))
 	}
+	transaction.commit()
 
 	if a.d.GitRemoteReady() {
 		if err := a.d.GitPush(false); err != nil {
@@ -262,30 +271,6 @@ func (a *Forj) createDeployment(deploy string) error {
 	return nil
 }
 
-func (a *Forj) define_drivers_execution_order() (instances []string) {
-	instances = make([]string, len(a.drivers))
-	drivers := make(map[string]*drivers.Driver)
-	index := 0
-	for name, driver := range a.drivers {
-		drivers[name] = driver
-	}
-	// first: execute upstream infra
-	if instance := a.f.GetInfraInstance(); instance != "" {
-		instances[index] = instance
-		index++
-		delete(drivers, instance)
-		gotrace.Trace("execution order will start with '%s'", instance)
-	}
-
-	// Get all others.
-	for name := range drivers {
-		instances[index] = name
-		index++
-	}
-	gotrace.Trace("Execution order selected: '%s'", strings.Join(instances, "', '"))
-	return
-}
-
 // Search for upstreams drivers and with or without --infra-upstream setting, the appropriate upstream will define the infra-repo upstream instance to use.
 // It sets/Initialize
 // - Forj.w.Instance        : Instance name
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"sort"
 
 	"github.com/forj-oss/forjj-modules/trace"
 	"github.com/forj-oss/goforjj"
@@ -147,6 +148,40 @@ func (d *Secure) Load() error {
 	return nil
 }
 
+// LoadEnv loads the security file of another deployment environment.
+// Environments already known (global and current deployment) are not reloaded.
+func (d *Secure) LoadEnv(env string) error {
+	if d == nil || d.secrets.Envs == nil {
+		return fmt.Errorf("Secure object is not initialized")
+	}
+	if _, found := d.secrets.Envs[env]; found {
+		return nil
+	}
+	d.SetDefaultFile(env)
+	return d.secrets.Envs[env].load(env, true)
+}
+
+// Keys returns the sorted list of object/instance/key defined in a deployment environment.
+func (d *Secure) Keys(env string) (keys []string) {
+	keys = []string{}
+	if d == nil {
+		return
+	}
+	v, found := d.secrets.Envs[env]
+	if !found {
+		return
+	}
+	for objName, instances := range v.Objects {
+		for instanceName, values := range instances {
+			for keyName := range values {
+				keys = append(keys, objName+"/"+instanceName+"/"+keyName)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return
+}
+
 // Save security files (global + deployment one)
 func (d *Secure) Save() error {
 	if d == nil {
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"forjj/forjfile"
+	"os"
+	"os/exec"
+	"os/user"
+	"sort"
+	"strings"
+
+	"github.com/alecthomas/kingpin"
+	"github.com/forj-oss/forjj-modules/trace"
+)
+
+// deploymentRun is the result of update/maintain on one deployment, with --all or --type.
+type deploymentRun struct {
+	Deployment string      `json:"deployment"`
+	Type       string      `json:"type"`
+	Status     string      `json:"status"` // success, failed or skipped (with --fail-fast)
+	Error      string      `json:"error,omitempty"`
+	Output     *forjOutput `json:"output,omitempty"` // JSON document of the deployment run, in json mode.
+}
+
+// selectDeployments returns the deployments selected by --all or --type, in the deployment types order.
+// (DEV, TEST and PRO by default)
+// found is false if none of those flags are set.
+func (a *Forj) selectDeployments() (deploys []*forjfile.DeploymentStruct, found bool, err error) {
+	all, _, _ := a.cli.GetBoolValue("_app", "forjj", deploymentsAllF)
+	deployType, _, _, _ := a.cli.GetStringValue("_app", "forjj", deploymentsTypeF)
+	if !all && deployType == "" {
+		return
+	}
+	found = true
+	if all && deployType != "" {
+		return nil, found, fmt.Errorf("--%s and --%s cannot be used together", deploymentsAllF, deploymentsTypeF)
+	}
+	if v, argFound, _, _ := a.cli.GetStringValue("_app", "forjj", deployToArg); argFound && v != "" {
+		return nil, found, fmt.Errorf("A deployment environment ('%s') cannot be given with --%s or --%s", v, deploymentsAllF, deploymentsTypeF)
+	}
+
+	deployTypes := a.f.DeploymentTypes()
+	list := a.f.GetDeployments()
+	if deployType != "" {
+		deployType = strings.ToUpper(deployType)
+		if _, typeFound := deployTypes.Get(deployType); !typeFound {
+			return nil, found, fmt.Errorf("Unknown deployment type '%s'. Must be one of %s", deployType, strings.Join(deployTypes.Names(), ", "))
+		}
+		list, _ = a.f.GetDeploymentType(deployType)
+	}
+	for _, deploy := range list {
+		deploys = append(deploys, deploy)
+	}
+	if len(deploys) == 0 {
+		return nil, found, fmt.Errorf("No deployment of type '%s' found in your Forjfile", deployType)
+	}
+
+	sort.Slice(deploys, func(i, j int) bool {
+		if rankI, rankJ := deployTypes.Rank(deploys[i].Type), deployTypes.Rank(deploys[j].Type); rankI != rankJ {
+			return rankI < rankJ
+		}
+		return deploys[i].Name() < deploys[j].Name()
+	})
+	return
+}
+
+// checkDeploymentTypeRules verifies the current deployment action respects its deployment type rules.
+//
+// update requires --deploy-publish if the type requires it. maintain is restricted to the type maintainers.
+func (a *Forj) checkDeploymentTypeRules(deployPublish bool) error {
+	switch a.contextAction {
+	case upd_act:
+		return a.f.CheckUpdateRules(a.f.GetDeployment(), deployPublish)
+	case maint_act:
+		return a.f.CheckMaintainRules(a.f.GetDeployment(), maintainerName())
+	}
+	return nil
+}
+
+// maintainerName returns the system user running forjj.
+//
+// It is compared with Forjfile users, so the maintainers check is advisory only. (See Forge.CheckMaintainRules)
+func maintainerName() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return ""
+}
+
+// runOnDeployments runs the current action on each deployment selected by --all or --type.
+//
+// Each deployment is run by a dedicated forjj process, started as `forjj <action> <deployment>` with
+// the same flags. So, each one has its own in memory Forjfile, flows, credentials and drivers.
+// All deployments are run and failures are reported at the end. With --fail-fast, the deployments next to
+// a failure are skipped.
+func (a *Forj) runOnDeployments() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("Unable to identify the forjj binary. %s", err)
+	}
+	failFast, _, _ := a.cli.GetBoolValue("_app", "forjj", deploymentsFailFastF)
+
+	results := make([]deploymentRun, 0, len(a.deployments))
+	failed := []string{}
+	for _, deploy := range a.deployments {
+		result := deploymentRun{Deployment: deploy.Name(), Type: deploy.Type}
+		if failFast && len(failed) > 0 {
+			result.Status = "skipped"
+			results = append(results, result)
+			continue
+		}
+
+		args, err := a.deploymentArgs(deploy.Name())
+		if err != nil {
+			return err
+		}
+		gotrace.Info("Running %s on '%s' deployment (%s).", a.contextAction, deploy.Name(), deploy.Type)
+		cmd := exec.Command(exe, args...)
+		cmd.Stdin = os.Stdin
+		cmd.Stderr = os.Stderr
+		var stdout bytes.Buffer
+		if a.output.isJSON() {
+			cmd.Stdout = &stdout
+		} else {
+			cmd.Stdout = a.output.writer()
+		}
+
+		err = cmd.Run()
+		if stdout.Len() > 0 {
+			result.Output = new(forjOutput)
+			if e := json.Unmarshal(stdout.Bytes(), result.Output); e != nil {
+				gotrace.Error("Unable to read the '%s' deployment JSON output. %s", deploy.Name(), e)
+				result.Output = nil
+			}
+		}
+
+		if err == nil {
+			result.Status = "success"
+		} else {
+			result.Status = "failed"
+			result.Error = err.Error()
+			if result.Output != nil && len(result.Output.Errors) > 0 {
+				result.Error = result.Output.Errors[0].Message
+			}
+			failed = append(failed, fmt.Sprintf("'%s': %s", deploy.Name(), result.Error))
+		}
+		results = append(results, result)
+	}
+
+	a.output.setData(results)
+	if !a.output.isJSON() {
+		report := fmt.Sprintf("Deployments %s report:\n", a.contextAction)
+		for _, result := range results {
+			report += fmt.Sprintf("- %s (%s): %s\n", result.Deployment, result.Type, result.Status)
+		}
+		a.output.printf("%s", report)
+	}
+	switch len(failed) {
+	case 0:
+		return nil
+	case 1:
+		return fmt.Errorf("%s failed on deployment %s", a.contextAction, failed[0])
+	}
+	return fmt.Errorf("%s failed on %d deployments:\n- %s", a.contextAction, len(failed), strings.Join(failed, "\n- "))
+}
+
+// deploymentArgs returns the forjj arguments to run the action on one deployment.
+//
+// They are rebuilt from the command line parsed: --all, --type and --fail-fast are removed and the deployment is given as
+// the action argument.
+func (a *Forj) deploymentArgs(deploy string) ([]string, error) {
+	context, err := a.app.ParseContext(os.Args[1:])
+	if err != nil {
+		return nil, fmt.Errorf("Unable to parse the command line. %s", err)
+	}
+	return deploymentArgs(context, deploy), nil
+}
+
+// deploymentArgs returns the commands parsed, followed by the deployment, the other arguments and flags.
+func deploymentArgs(context *kingpin.ParseContext, deploy string) (ret []string) {
+	args := []string{}
+	flags := []string{}
+	for _, element := range context.Elements {
+		value := ""
+		if element.Value != nil {
+			value = *element.Value
+		}
+		switch clause := element.Clause.(type) {
+		case *kingpin.CmdClause:
+			ret = append(ret, clause.Model().Name)
+		case *kingpin.ArgClause:
+			if clause.Model().Name != deployToArg {
+				args = append(args, value)
+			}
+		case *kingpin.FlagClause:
+			flag := clause.Model()
+			switch {
+			case flag.Name == deploymentsAllF || flag.Name == deploymentsTypeF || flag.Name == deploymentsFailFastF:
+			case flag.IsBoolFlag() && value == "false":
+				flags = append(flags, "--no-"+flag.Name)
+			case flag.IsBoolFlag():
+				flags = append(flags, "--"+flag.Name)
+			default:
+				flags = append(flags, "--"+flag.Name+"="+value)
+			}
+		}
+	}
+	ret = append(ret, deploy)
+	ret = append(ret, args...)
+	return append(ret, flags...)
+}
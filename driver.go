@@ -34,12 +34,11 @@ func (a *Forj) do_driver_task(action, instance string) (err error, aborted bool)
 		return fmt.Errorf("Internal error: Invalid action '%s'. Supports only 'create' and 'update'.", action), false
 	}
 
-	if err = a.driver_init(instance); err != nil {
+	d, err := a.driver_init(instance)
+	if err != nil {
 		return
 	}
 
-	d := a.CurrentPluginDriver
-
 	// Add ref to this driver in the forjj infra repo
 	//a.o.Drivers[instance] = d
 
@@ -112,32 +111,31 @@ func (a *Forj) do_driver_add(d *drivers.Driver) error {
 
 	// Add source files
 	if err := d.GitAddPluginFiles(a.moveTo); err != nil {
-		return fmt.Errorf("Issue to add driver '%s' generated files. %s", a.CurrentPluginDriver.Name, err)
+		return fmt.Errorf("Issue to add driver '%s' generated files. %s", d.Name, err)
 	}
 
 	// Check about uncontrolled files. Existing if one uncontrolled file is found
-	if status := git.GetStatus(); status.Err != nil {
-		return fmt.Errorf("Issue to check git status. %s", status.Err)
-	} else {
-		if num := status.CountUntracked(); num > 0 {
-			log.Print("Following files created by the plugin are not controlled by the plugin. You must fix it manually and contact the plugin maintainer to fix this issue.")
-			log.Printf("files: %s", strings.Join(status.Untracked(), ", "))
-			return fmt.Errorf("Unable to complete commit process. '%d' Uncontrolled files found", num)
-		}
+	// When drivers run in parallel, other drivers can write in the same repositories at the same time.
+	// So, only the driver files directories are checked.
+	parallel, _ := a.getParallel()
+	allFiles := a.driversLock == nil || parallel <= 1
+	if files, err := d.GitUncontrolledFiles(a.moveTo, allFiles); err != nil {
+		return err
+	} else if num := len(files); num > 0 {
+		log.Print("Following files created by the plugin are not controlled by the plugin. You must fix it manually and contact the plugin maintainer to fix this issue.")
+		log.Printf("files: %s", strings.Join(files, ", "))
+		return fmt.Errorf("Unable to complete commit process. '%d' Uncontrolled files found", num)
 	}
 	return nil
 }
 
-// Define starting on this driver
-// Forj.CurrentPluginDriver set
-func (a *Forj) driver_init(instance string) error {
-
+// driver_init returns the driver of the instance to start on.
+func (a *Forj) driver_init(instance string) (*drivers.Driver, error) {
 	d, found := a.drivers[instance]
 	if !found {
-		return fmt.Errorf("Internal error: Unable to find %s from drivers.", instance)
+		return nil, fmt.Errorf("Internal error: Unable to find %s from drivers.", instance)
 	}
-	a.CurrentPluginDriver = d
-	return nil
+	return d, nil
 }
 
 func (a *Forj) driver_cleanup_all() {
@@ -171,7 +169,6 @@ func (a *Forj) init_driver_flags_for(d *drivers.Driver, option_name, command, fo
 }
 
 // Start driver task.
-// Forj.CurrentPluginDriver is set to the current driver
 func (a *Forj) driver_do(d *drivers.Driver, instance_name, action string, args ...string) (err error, aborted bool) {
 	defer log.Print("-------------------------------------------")
 	log.Print("-------------------------------------------")
@@ -215,31 +212,27 @@ func (a *Forj) driver_do(d *drivers.Driver, instance_name, action string, args .
 	d.Plugin.ServiceAddEnv("LOGNAME", "$LOGNAME", false)
 	d.Plugin.Yaml.Runtime.Docker.Env["LOGNAME"] = "$LOGNAME"
 
-	if err := d.Plugin.PluginStartService(); err != nil {
+	// Plugin services are started concurrently when drivers are executed in parallel.
+	if err := a.pluginUnlocked(d.Plugin.PluginStartService); err != nil {
 		return err, false
 	}
 
-	plugin_payload := goforjj.NewReqData()
-
-	// Load all internal Forjj data, identified by 'forjj-*'
-	a.LoadInternalData()
-	a.GetForjjFlags(plugin_payload, d, common_acts)
-	a.GetForjjFlags(plugin_payload, d, action)
-	if err := a.GetObjectsData(plugin_payload, d, action); err != nil {
-		return fmt.Errorf("Unable to Get Object data on '%s'. %s", instance_name, err), aborted
-	}
-	err = a.AddReqDeployment(plugin_payload)
+	plugin_payload, err := a.buildPluginPayload(d, instance_name, action)
 	if err != nil {
-		return fmt.Errorf("Unable to %s. %s. You may need to execute a forjj update to a deployment environment", action, err), false
+		return err, false
 	}
 
-	d.Plugin.Result, err = d.Plugin.PluginRunAction(action, plugin_payload)
+	err = a.pluginUnlocked(func() (err error) {
+		d.Plugin.Result, err = d.Plugin.PluginRunAction(action, plugin_payload)
+		return
+	})
 	if err != nil {
 		return fmt.Errorf("Internal Error: %s", err), false
 	}
 	if d.Plugin.Result == nil {
 		return fmt.Errorf("An error occured in '%s' plugin. No data has been returned. Please check plugin logs.", instance_name), false
 	}
+	a.output.addPluginResult(d, instance_name, action)
 
 	termBrown, termReset := utils.DefColor(33)
 	for _, line := range strings.Split(d.Plugin.Result.Data.Status, "\n") {
@@ -262,6 +255,8 @@ func (a *Forj) driver_do(d *drivers.Driver, instance_name, action string, args .
 		return err, aborted
 	}
 
+	a.savePluginState(d, instance_name, action, plugin_payload)
+
 	// Dispatch driver information in Forjj
 
 	// Deliver list of Remotes in Internal Forjfile
@@ -321,6 +316,23 @@ func (a *Forj) driver_do(d *drivers.Driver, instance_name, action string, args .
 	return
 }
 
+// buildPluginPayload creates the payload sent to the plugin instance for the action given.
+func (a *Forj) buildPluginPayload(d *drivers.Driver, instance_name, action string) (*goforjj.PluginReqData, error) {
+	plugin_payload := goforjj.NewReqData()
+
+	// Load all internal Forjj data, identified by 'forjj-*'
+	a.LoadInternalData(d)
+	a.GetForjjFlags(plugin_payload, d, common_acts)
+	a.GetForjjFlags(plugin_payload, d, action)
+	if err := a.GetObjectsData(plugin_payload, d, action); err != nil {
+		return nil, fmt.Errorf("Unable to Get Object data on '%s'. %s", instance_name, err)
+	}
+	if err := a.AddReqDeployment(plugin_payload); err != nil {
+		return nil, fmt.Errorf("Unable to %s. %s. You may need to execute a forjj update to a deployment environment", action, err)
+	}
+	return plugin_payload, nil
+}
+
 func (a *Forj) DriverGet(instance string) (d *drivers.Driver) {
 	var found bool
 
@@ -65,22 +65,59 @@ func RunInPath(where string, moveTo func(string) (string, error), runIn func() e
 	return nil
 }
 
+// GitUncontrolledFiles returns files not added to GIT, found in repositories where the plugin wrote files.
+// If allFiles is false, only directories of the plugin files are checked.
+func (d *Driver) GitUncontrolledFiles(moveTo func(string) (string, error), allFiles bool) (files []string, err error) {
+	for where, pluginFiles := range d.Plugin.Result.Data.Files {
+		dirs := make([]string, 0, len(pluginFiles))
+		found := make(map[string]bool)
+		for _, file := range d.pluginFilesPath(where, pluginFiles) {
+			if dir := path.Dir(file); !found[dir] {
+				found[dir] = true
+				dirs = append(dirs, dir)
+			}
+		}
+		if len(dirs) == 0 {
+			continue
+		}
+		if allFiles {
+			dirs = nil
+		}
+		if err = RunInPath(where, moveTo, func() error {
+			status := git.GetStatus(dirs...)
+			if status.Err != nil {
+				return fmt.Errorf("Issue to check git status. %s", status.Err)
+			}
+			files = append(files, status.Untracked()...)
+			return nil
+		}); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// pluginFilesPath returns the path of plugin files in the repository `where`.
+func (d *Driver) pluginFilesPath(where string, files []string) (ret []string) {
+	ret = make([]string, len(files))
+	for iCount, file := range files {
+		if where == goforjj.FilesSource {
+			ret[iCount] = path.Join("apps", d.DriverType, file)
+		} else {
+			ret[iCount] = file
+		}
+	}
+	return
+}
+
 func (d *Driver) gitAddPluginFiles(where string, files []string) error {
 	if files == nil {
 		return nil
 	}
-	fileToAdd := make([]string, len(files))
 	if len(files) > 0 {
 		git.ShowGitPath()
 	}
-	for iCount, file := range files {
-		if where == goforjj.FilesSource {
-			fileToAdd[iCount] = path.Join("apps", d.DriverType, file)
-		} else {
-			fileToAdd[iCount] = file
-		}
-	}
-	if i := git.Add(fileToAdd); i > 0 {
+	if i := git.Add(d.pluginFilesPath(where, files)); i > 0 {
 		return fmt.Errorf("Issue while adding code to git. RC=%d", i)
 	}
 	return nil
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"fmt"
+	"forjj/forjfile"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/forj-oss/forjj-modules/trace"
+)
+
+const defaultParallel = 1
+
+// driversGraph is the dependency graph between driver instances.
+type driversGraph struct {
+	dependsOn map[string]map[string]bool // key: instance, value: list of instances to execute before.
+}
+
+func newDriversGraph() *driversGraph {
+	g := new(driversGraph)
+	g.dependsOn = make(map[string]map[string]bool)
+	return g
+}
+
+// addInstance adds a new instance in the graph.
+func (g *driversGraph) addInstance(instance string) {
+	if _, found := g.dependsOn[instance]; !found {
+		g.dependsOn[instance] = make(map[string]bool)
+	}
+}
+
+// addDependency declares that instance must be executed after dependsOn.
+func (g *driversGraph) addDependency(instance, dependsOn string) error {
+	if _, found := g.dependsOn[instance]; !found {
+		return fmt.Errorf("Unknown application instance '%s'", instance)
+	}
+	if _, found := g.dependsOn[dependsOn]; !found {
+		return fmt.Errorf("'%s' depends on an unknown application instance '%s'", instance, dependsOn)
+	}
+	if instance == dependsOn {
+		return nil
+	}
+	g.dependsOn[instance][dependsOn] = true
+	return nil
+}
+
+// requires returns true if instance depends on dependsOn, directly or not.
+func (g *driversGraph) requires(instance, dependsOn string) bool {
+	visited := make(map[string]bool)
+	toVisit := []string{instance}
+	for len(toVisit) > 0 {
+		current := toVisit[0]
+		toVisit = toVisit[1:]
+		for dep := range g.dependsOn[current] {
+			if dep == dependsOn {
+				return true
+			}
+			if !visited[dep] {
+				visited[dep] = true
+				toVisit = append(toVisit, dep)
+			}
+		}
+	}
+	return false
+}
+
+// order returns a reproducible list of instances where each instance comes after its dependencies.
+// If the graph has a cycle, an error is returned with instances involved.
+func (g *driversGraph) order() (instances []string, err error) {
+	remaining := make(map[string]int)
+	for instance, deps := range g.dependsOn {
+		remaining[instance] = len(deps)
+	}
+
+	instances = make([]string, 0, len(g.dependsOn))
+	ready := g.ready(remaining)
+	for len(ready) > 0 {
+		instance := ready[0]
+		instances = append(instances, instance)
+		delete(remaining, instance)
+		for _, dependent := range g.dependents(instance) {
+			remaining[dependent]--
+		}
+		ready = g.ready(remaining)
+	}
+
+	if len(remaining) > 0 {
+		cycle := make([]string, 0, len(remaining))
+		for instance := range remaining {
+			cycle = append(cycle, instance)
+		}
+		sort.Strings(cycle)
+		return nil, fmt.Errorf("Dependency cycle detected. Unable to order applications '%s'. Check your applications 'depends-on' and repositories 'in-relation-with'", strings.Join(cycle, "', '"))
+	}
+	return
+}
+
+// ready returns the sorted list of instances which have no more dependencies to wait for.
+func (g *driversGraph) ready(remaining map[string]int) (ret []string) {
+	ret = make([]string, 0, len(remaining))
+	for instance, count := range remaining {
+		if count == 0 {
+			ret = append(ret, instance)
+		}
+	}
+	sort.Strings(ret)
+	return
+}
+
+// dependents returns the sorted list of instances which depend on the instance given.
+func (g *driversGraph) dependents(instance string) (ret []string) {
+	for dependent, deps := range g.dependsOn {
+		if deps[instance] {
+			ret = append(ret, dependent)
+		}
+	}
+	sort.Strings(ret)
+	return
+}
+
+// run executes task on each instance, with at most `parallel` instances at the same time.
+// An instance is started only when all its dependencies were successfully executed.
+// On first error, no new instance is started and the error is returned when running ones are completed.
+func (g *driversGraph) run(parallel int, task func(instance string) error) error {
+	if _, err := g.order(); err != nil {
+		return err
+	}
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	type taskResult struct {
+		instance string
+		err      error
+	}
+
+	remaining := make(map[string]int)
+	for instance, deps := range g.dependsOn {
+		remaining[instance] = len(deps)
+	}
+	results := make(chan taskResult)
+	running := 0
+	var firstErr error
+
+	for {
+		for _, instance := range g.ready(remaining) {
+			if firstErr != nil || running >= parallel {
+				break
+			}
+			delete(remaining, instance)
+			running++
+			go func(instance string) {
+				results <- taskResult{instance, task(instance)}
+			}(instance)
+		}
+		if running == 0 {
+			break
+		}
+
+		result := <-results
+		running--
+		if result.err != nil {
+			if firstErr == nil {
+				firstErr = result.err
+			}
+			continue
+		}
+		for _, dependent := range g.dependents(result.instance) {
+			remaining[dependent]--
+		}
+	}
+	return firstErr
+}
+
+// define_drivers_execution_graph builds the dependency graph between driver instances.
+//
+// - An application executes after applications listed in its `depends-on`.
+// - An application in relation with a repository executes after the repository upstream application.
+// - The infra upstream instance is executed first, except before applications it depends on.
+func (a *Forj) define_drivers_execution_graph() (g *driversGraph, err error) {
+	g = newDriversGraph()
+	for instance := range a.drivers {
+		g.addInstance(instance)
+	}
+
+	if ffd := a.f.InMemForjfile(); ffd != nil {
+		if err = a.define_drivers_dependencies(g, ffd); err != nil {
+			return nil, err
+		}
+	}
+
+	infraInstance := a.f.GetInfraInstance()
+	if _, found := a.drivers[infraInstance]; !found {
+		return
+	}
+	for instance := range a.drivers {
+		if g.requires(infraInstance, instance) {
+			gotrace.Trace("'%s' is not executed after the infra instance '%s' as it depends on it.", infraInstance, instance)
+			continue
+		}
+		if err = g.addDependency(instance, infraInstance); err != nil {
+			return nil, fmt.Errorf("Unable to execute '%s' after the infra instance. %s", instance, err)
+		}
+	}
+	return
+}
+
+// define_drivers_dependencies adds dependencies defined by the Forjfile in the graph.
+func (a *Forj) define_drivers_dependencies(g *driversGraph, ffd *forjfile.DeployForgeYaml) error {
+
+	for instance := range a.drivers {
+		app, found := ffd.Apps[instance]
+		if !found {
+			continue
+		}
+		for _, dependsOn := range app.GetDependsOn() {
+			if err := g.addDependency(instance, dependsOn); err != nil {
+				return fmt.Errorf("Invalid application '%s' %s. %s", instance, forjfile.AppDependsOn, err)
+			}
+		}
+	}
+
+	for repoName, repo := range ffd.Repos {
+		apps, err := repo.GetApps()
+		if err != nil {
+			return err
+		}
+		upstream, found := apps["upstream"]
+		if !found {
+			continue
+		}
+		if _, found := a.drivers[upstream.Name()]; !found {
+			continue
+		}
+		for relName, app := range apps {
+			if _, found := a.drivers[app.Name()]; !found || relName == "upstream" {
+				continue
+			}
+			if err := g.addDependency(app.Name(), upstream.Name()); err != nil {
+				return fmt.Errorf("Invalid repository '%s' applications. %s", repoName, err)
+			}
+			gotrace.Trace("'%s' depends on '%s', as upstream of repository '%s'", app.Name(), upstream.Name(), repoName)
+		}
+	}
+	return nil
+}
+
+// do_drivers_run executes task on all driver instances, following their dependencies.
+// Independent instances are executed concurrently, limited by `--parallel`.
+//
+// forjj internal data are not thread safe. So, task is executed with a.driversLock locked.
+// Only plugins calls (see a.pluginUnlocked) are executed concurrently. As plugins can write in the infra and
+// deploy repositories meanwhile, GIT checks are limited to the driver files when `--parallel` is more than 1. (see do_driver_add)
+func (a *Forj) do_drivers_run(task func(instance string) error) error {
+	g, err := a.define_drivers_execution_graph()
+	if err != nil {
+		return err
+	}
+
+	if order, err := g.order(); err != nil {
+		return err
+	} else {
+		gotrace.Trace("Execution order selected: '%s'", strings.Join(order, "', '"))
+	}
+
+	parallel, err := a.getParallel()
+	if err != nil {
+		return err
+	}
+	a.driversLock = new(sync.Mutex)
+	defer func() { a.driversLock = nil }()
+
+	return g.run(parallel, func(instance string) error {
+		a.driversLock.Lock()
+		defer a.driversLock.Unlock()
+		return task(instance)
+	})
+}
+
+// pluginUnlocked executes a plugin call out of the forjj internal data lock, when drivers are run
+// by do_drivers_run.
+func (a *Forj) pluginUnlocked(call func() error) error {
+	if a.driversLock == nil {
+		return call()
+	}
+	a.driversLock.Unlock()
+	defer a.driversLock.Lock()
+	return call()
+}
+
+// getParallel returns the maximum number of driver instances to run at the same time.
+func (a *Forj) getParallel() (int, error) {
+	parallel, found := a.parallel[a.contextAction]
+	if !found || parallel == nil {
+		return defaultParallel, nil
+	}
+	if *parallel < 1 {
+		return 0, fmt.Errorf("Invalid --%s value '%d'. It must be 1 or more", parallel_f, *parallel)
+	}
+	return *parallel, nil
+}
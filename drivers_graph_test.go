@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestDriversGraph returns a graph from a list of instances and their dependencies.
+func newTestDriversGraph(deps map[string][]string) *driversGraph {
+	g := newDriversGraph()
+	for instance := range deps {
+		g.addInstance(instance)
+	}
+	for instance, dependsOn := range deps {
+		for _, dep := range dependsOn {
+			g.addDependency(instance, dep)
+		}
+	}
+	return g
+}
+
+func TestDriversGraphOrder(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		testCase string
+		deps     map[string][]string
+		order    []string
+		err      string
+	}{
+		{
+			testCase: "when instances have no dependencies",
+			deps:     map[string][]string{"jenkins": nil, "github": nil, "artifactory": nil},
+			order:    []string{"artifactory", "github", "jenkins"},
+		},
+		{
+			testCase: "when instances depend on each other",
+			deps:     map[string][]string{"jenkins": {"github"}, "github": nil, "artifactory": {"jenkins"}},
+			order:    []string{"github", "jenkins", "artifactory"},
+		},
+		{
+			testCase: "when instances depend on the same instance",
+			deps:     map[string][]string{"jenkins": {"github"}, "github": nil, "artifactory": {"github"}, "slack": {"jenkins", "artifactory"}},
+			order:    []string{"github", "artifactory", "jenkins", "slack"},
+		},
+		{
+			testCase: "when instances have a cycle",
+			deps:     map[string][]string{"jenkins": {"github"}, "github": {"artifactory"}, "artifactory": {"jenkins"}, "slack": nil},
+			err:      "Unable to order applications 'artifactory', 'github', 'jenkins'",
+		},
+	}
+
+	for _, test := range tests {
+		g := newTestDriversGraph(test.deps)
+		order, err := g.order()
+		if test.err != "" {
+			if assert.Errorf(err, "Expect an error %s", test.testCase) {
+				assert.Containsf(err.Error(), test.err, "Expect instances of the cycle %s", test.testCase)
+			}
+			continue
+		}
+		assert.NoErrorf(err, "Expect no error %s", test.testCase)
+		assert.Equalf(test.order, order, "Expect the order %s", test.testCase)
+		for i := 0; i < 10; i++ {
+			order, _ = newTestDriversGraph(test.deps).order()
+			assert.Equalf(test.order, order, "Expect the same order on each run %s", test.testCase)
+		}
+	}
+}
+
+func TestDriversGraphRequires(t *testing.T) {
+	assert := assert.New(t)
+
+	g := newTestDriversGraph(map[string][]string{"github": {"vault"}, "vault": {"consul"}, "consul": nil, "jenkins": nil})
+
+	/*************************************/
+	testCase := "when an instance depends indirectly on another"
+
+	assert.Truef(g.requires("github", "consul"), "Expect github to require consul %s", testCase)
+	assert.Falsef(g.requires("consul", "github"), "Expect consul to not require github %s", testCase)
+	assert.Falsef(g.requires("github", "jenkins"), "Expect github to not require jenkins %s", testCase)
+}
+
+func TestDriversGraphRun(t *testing.T) {
+	assert := assert.New(t)
+
+	deps := map[string][]string{"a": nil, "b": nil, "c": nil, "d": nil, "e": {"a", "b"}}
+	tests := []struct {
+		testCase string
+		parallel int
+		fail     string
+		started  []string // Instances expected to start, in any order. nil to expect all.
+	}{
+		{testCase: "when instances run one by one", parallel: 1},
+		{testCase: "when 2 instances run at the same time", parallel: 2},
+		{testCase: "when all instances can run at the same time", parallel: 10},
+		{testCase: "when parallel is invalid", parallel: 0},
+		{testCase: "when a dependency fails", parallel: 1, fail: "a", started: []string{"a"}},
+	}
+
+	for _, test := range tests {
+		var lock sync.Mutex
+		running, maxRunning := 0, 0
+		done := make(map[string]bool)
+		started := []string{}
+
+		err := newTestDriversGraph(deps).run(test.parallel, func(instance string) error {
+			lock.Lock()
+			started = append(started, instance)
+			for _, dep := range deps[instance] {
+				assert.Truef(done[dep], "Expect '%s' to run after '%s' %s", instance, dep, test.testCase)
+			}
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			lock.Unlock()
+
+			time.Sleep(5 * time.Millisecond)
+
+			lock.Lock()
+			defer lock.Unlock()
+			running--
+			if instance == test.fail {
+				return fmt.Errorf("%s failed", instance)
+			}
+			done[instance] = true
+			return nil
+		})
+
+		maxExpected := test.parallel
+		if maxExpected < 1 {
+			maxExpected = 1
+		}
+		assert.Truef(maxRunning <= maxExpected, "Expect at most %d instances running. Got %d %s", maxExpected, maxRunning, test.testCase)
+		if test.fail != "" {
+			assert.EqualErrorf(err, test.fail+" failed", "Expect the error %s", test.testCase)
+			assert.Equalf(test.started, started, "Expect no instances started after the failure %s", test.testCase)
+			continue
+		}
+		assert.NoErrorf(err, "Expect no error %s", test.testCase)
+		assert.Lenf(started, len(deps), "Expect all instances to run %s", test.testCase)
+	}
+}
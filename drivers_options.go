@@ -92,7 +92,7 @@ func (a *Forj) read_driver(instance_name string) (err error) {
 				if v := plugin.ExtendRelPath; v != "" {
 					tmpl := template.New("extended")
 					a.f.BuildForjfileInMem()
-					relPath, err := utils.Evaluate(v, tmpl, a.f.Model(instance_name), template.FuncMap{})
+					relPath, err := utils.Evaluate(v, tmpl, a.f.Model(instance_name), a.f.InMemForjfile().TemplateFuncs())
 					if err != nil {
 						return nil, fmt.Errorf("Unable to interpret 'extend_relative_path'. %s", err)
 					}
@@ -413,11 +413,11 @@ func (a *Forj) objectGetInstances(object_name string) (ret []string) {
 
 func (a *Forj) copyCliObjectData(ffd *forjfile.DeployForgeYaml, object_name, instance, flag_name, def_value string) {
 	if v, found, _, _ := a.cli.GetStringValue(object_name, instance, flag_name); found && v != "" {
-		ffd.Set("forjj", object_name, instance, flag_name, v)
+		ffd.Set("cli", object_name, instance, flag_name, v)
 		gotrace.Trace("Set %s/%s:%s value to Forjfile from cli.", object_name, instance, flag_name)
 	} else {
 		if def_value != "" {
-			ffd.SetDefault("forjj", object_name, instance, flag_name, def_value)
+			ffd.SetDefault("plugin-default", object_name, instance, flag_name, def_value)
 			gotrace.Trace("Setting Forjfile flag '%s/%s:%s' default value to '%s'",
 				object_name, instance, flag_name, def_value)
 		}
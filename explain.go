@@ -0,0 +1,275 @@
+package main
+
+import (
+	"fmt"
+	"forjj/creds"
+	"forjj/forjfile"
+	"forjj/sources_info"
+	"sort"
+	"strings"
+)
+
+// Explain layers, in the order they are applied.
+const (
+	explainMaster    = "master Forjfile"
+	explainInherited = "inherited deployment Forjfile"
+	explainDeploy    = "deployment Forjfile"
+	explainForjj     = "forjj"
+	explainFlow      = "flow"
+	explainDefaults  = "plugin defaults"
+	explainCreds     = "creds"
+)
+
+func (a *Forj) explainAction(string) error {
+	if err := a.Explain(); err != nil {
+		return fmt.Errorf("Forjj explain issue. %s", err)
+	}
+	return nil
+}
+
+// explainedKey is the explanation of an object instance key value.
+type explainedKey struct {
+	Key        string         `json:"key"`
+	Value      string         `json:"value"`
+	Layer      string         `json:"layer"`            // Layer which defines the final value.
+	Source     string         `json:"source,omitempty"` // Source recorded by the object. Ex: forjj, flow, cli.
+	Overridden []explainValue `json:"overridden,omitempty"`
+	// Changes recorded in the in memory Forjfile. Ex: files loaded, flows, defaults.
+	History []sourcesinfo.Source `json:"history,omitempty"`
+}
+
+// explainValue is a value found in one layer.
+type explainValue struct {
+	Layer  string `json:"layer"`
+	Value  string `json:"value"`
+	Source string `json:"source,omitempty"`
+}
+
+// explainLayers collects values of an object instance, layer after layer.
+type explainLayers struct {
+	object, instance string
+	key              string // If set, only this key is explained.
+	keys             map[string]bool
+	values           map[string][]explainValue
+}
+
+func newExplainLayers(object, instance, key string) (ret *explainLayers) {
+	ret = new(explainLayers)
+	ret.object = object
+	ret.instance = instance
+	ret.key = key
+	ret.keys = make(map[string]bool)
+	ret.values = make(map[string][]explainValue)
+	if key != "" {
+		ret.keys[key] = true
+	}
+	return
+}
+
+// addKeys registers keys to explain, except if only one key is explained.
+func (e *explainLayers) addKeys(keys ...string) {
+	if e.key != "" {
+		return
+	}
+	for _, key := range keys {
+		e.keys[key] = true
+	}
+}
+
+// addValue records a value found in a layer.
+func (e *explainLayers) addValue(layer, key, value, source string) {
+	if !e.keys[key] {
+		return
+	}
+	e.values[key] = append(e.values[key], explainValue{Layer: layer, Value: value, Source: source})
+}
+
+// add records the value of each key found in a Forjfile layer.
+// A layer which does not change the value of the previous layer is not recorded.
+func (e *explainLayers) add(layer string, ffd *forjfile.DeployForgeYaml, onlyChanges bool) {
+	if ffd == nil {
+		return
+	}
+	e.addKeys(ffd.GetKeys(e.object, e.instance)...)
+	for key := range e.keys {
+		v, found, source := ffd.GetString(e.object, e.instance, key)
+		if !found {
+			continue
+		}
+		values := e.values[key]
+		if onlyChanges && len(values) > 0 && values[len(values)-1].Value == v {
+			continue
+		}
+		e.addValue(layer, key, v, source)
+	}
+}
+
+// explain returns the final value of each key and values overridden.
+func (e *explainLayers) explain() (ret []explainedKey) {
+	keys := make([]string, 0, len(e.values))
+	for key := range e.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	ret = make([]explainedKey, 0, len(keys))
+	for _, key := range keys {
+		values := e.values[key]
+		final := values[len(values)-1]
+		explained := explainedKey{
+			Key:    key,
+			Value:  final.Value,
+			Layer:  final.Layer,
+			Source: final.Source,
+		}
+		for i := len(values) - 2; i >= 0; i-- {
+			explained.Overridden = append(explained.Overridden, values[i])
+		}
+		ret = append(ret, explained)
+	}
+	return
+}
+
+// Explain displays the final value of an object instance key (or all of them) for a deployment and
+// where it comes from.
+//
+// The in memory Forjfile is built as `forjj update` does, layer after layer:
+// master Forjfile, deployment Forjfile, forjj internal defaults, flows, plugin defaults and then creds.
+// If a value is found in creds, creds is chosen.
+func (a *Forj) Explain() error {
+	object, instance, key, err := a.explainPath()
+	if err != nil {
+		return err
+	}
+
+	deployTo := a.f.GetDeployment()
+	chain, err := a.f.GetDeployments().InheritanceChain(deployTo)
+	if err != nil {
+		return err
+	}
+
+	layers := newExplainLayers(object, instance, key)
+	layers.add(explainMaster, a.f.DeployForjfile(), false)
+	for _, deploy := range chain[:len(chain)-1] {
+		layers.add(fmt.Sprintf("%s '%s'", explainInherited, deploy.Name()), deploy.Details, false)
+	}
+	layers.add(explainDeploy, chain[len(chain)-1].Details, false)
+
+	// Build in memory representation from source files loaded.
+	if err := a.f.BuildForjfileInMem(); err != nil {
+		return err
+	}
+
+	ffd := a.f.InMemForjfile()
+
+	if err := a.DefineDeployRepositories(ffd, false); err != nil {
+		return fmt.Errorf("Issues to automatically add your deployment repositories. %s", err)
+	}
+
+	a.defineDeployContext()
+	layers.add(explainForjj, ffd, true)
+
+	if err := a.FlowInit(); err != nil {
+		return err
+	}
+
+	if err := a.define_infra_upstream(); err != nil {
+		return fmt.Errorf("Unable to identify a valid infra repository upstream. %s", err)
+	}
+
+	if err := a.FlowApply(); err != nil {
+		return fmt.Errorf("Unable to apply flows. %s", err)
+	}
+	layers.add(explainFlow, ffd, true)
+
+	if err := a.scanAndSetDefaults(ffd, creds.Global); err != nil {
+		return fmt.Errorf("Unable to set plugin defaults. %s", err)
+	}
+	layers.add(explainDefaults, ffd, true)
+
+	for credKey := range a.s.GetObjectInstance(object, instance) {
+		layers.addKeys(credKey)
+		if v, found, source, env := a.s.GetString(object, instance, credKey); found {
+			layers.addValue(explainCreds+" ("+env+")", credKey, explainSecret(v), source)
+		}
+	}
+
+	explained := layers.explain()
+	sources := ffd.GetSources(object, instance)
+	for i := range explained {
+		explained[i].History = sources.History(explained[i].Key)
+	}
+	if len(explained) == 0 {
+		if key != "" {
+			return fmt.Errorf("'%s/%s/%s' is not defined in deployment '%s'", object, instance, key, deployTo)
+		}
+		return fmt.Errorf("'%s/%s' is not defined in deployment '%s'", object, instance, deployTo)
+	}
+
+	if a.output.isJSON() {
+		a.output.setData(explained)
+		return nil
+	}
+
+	a.output.printf("Deployment '%s' - %s/%s:\n", deployTo, object, instance)
+	for _, v := range explained {
+		a.output.printf("\n%s: '%s'\n", v.Key, v.Value)
+		a.output.printf("  from %s%s\n", v.Layer, explainSource(v.Source))
+		for _, o := range v.Overridden {
+			a.output.printf("  overrides '%s' from %s%s\n", o.Value, o.Layer, explainSource(o.Source))
+		}
+		if len(v.History) > 0 {
+			a.output.println("  history:")
+		}
+		for _, h := range v.History {
+			a.output.printf("  - '%s'%s%s\n", h.Value, explainSource(h.Source), explainLocation(h.File, h.Line))
+		}
+	}
+	return nil
+}
+
+// explainPath returns the object, instance and key requested by `forjj explain`.
+//
+// ex: repo/myrepo/flow or repo/myrepo
+func (a *Forj) explainPath() (object, instance, key string, err error) {
+	v, found, _, _ := a.cli.GetStringValue("_app", "forjj", explainPathArg)
+	if !found || v == "" {
+		err = fmt.Errorf("Missing <object>/<instance>[/<key>] to explain")
+		return
+	}
+	path := strings.SplitN(v, "/", 3)
+	if len(path) < 2 || path[0] == "" || path[1] == "" {
+		err = fmt.Errorf("Invalid '%s'. Expect <object>/<instance>[/<key>]", v)
+		return
+	}
+	object, instance = path[0], path[1]
+	if len(path) == 3 {
+		key = path[2]
+	}
+	return
+}
+
+// explainSecret hides a creds value.
+func explainSecret(value string) string {
+	if value == "" {
+		return ""
+	}
+	return "***"
+}
+
+func explainLocation(file string, line int) string {
+	switch {
+	case file == "":
+		return ""
+	case line == 0:
+		return " in " + file
+	}
+	return fmt.Sprintf(" in %s:%d", file, line)
+}
+
+func explainSource(source string) string {
+	if source == "" {
+		return ""
+	}
+	return " (set by " + source + ")"
+}
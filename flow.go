@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/forj-oss/forjj-modules/trace"
 )
@@ -27,7 +28,15 @@ func (a *Forj) FlowApply() error {
 		bInError = true
 	}
 
-	for _, repo := range ffd.Repos {
+	// Repositories are applied in name order.
+	names := make([]string, 0, len(ffd.Repos))
+	for name := range ffd.Repos {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, repoName := range names {
+		repo := ffd.Repos[repoName]
 		flowToApply := defaultFlowToApply
 		if repo.Flow.Name != "" {
 			flowToApply = repo.Flow.Name
@@ -0,0 +1,74 @@
+package flow
+
+import (
+	"fmt"
+	"strings"
+)
+
+// composeFlow returns a flow with tasks and plugins types of flows it extends and includes.
+//
+// The extended flow is composed first, then each included flow, in order, then the flow itself. A task or a
+// plugin type defined later overrides the one with the same name. `chain` is the list of flows being
+// composed, to detect cycles. Each task keeps the file which defines it.
+func (fs *Flows) composeFlow(flowName string, chain []string) (*FlowDefine, error) {
+	for _, name := range chain {
+		if name == flowName {
+			return nil, fmt.Errorf("Flow cycle detected: %s", strings.Join(append(chain, flowName), " -> "))
+		}
+	}
+
+	flow, err := fs.readFlow(flowName)
+	if err != nil {
+		return nil, err
+	}
+	if flow.Extends == "" && len(flow.Include) == 0 {
+		return flow, nil
+	}
+
+	chain = append(chain, flowName)
+	composed := &FlowDefine{
+		Name:    flow.Name,
+		Extends: flow.Extends,
+		Include: flow.Include,
+		file:    flow.file,
+	}
+	parents := flow.Include
+	if flow.Extends != "" {
+		parents = append([]string{flow.Extends}, parents...)
+	}
+	for _, parentName := range parents {
+		parent, err := fs.composeFlow(parentName, chain)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to compose the flow '%s' from '%s'. %s", flowName, parentName, err)
+		}
+		composed.merge(parent)
+	}
+	composed.merge(flow)
+	return composed, nil
+}
+
+// merge adds tasks and plugins types of a flow, which override those with the same name.
+// The flow title is taken from the last flow which defines it.
+func (fd *FlowDefine) merge(flow *FlowDefine) {
+	if flow.Title != "" {
+		fd.Title = flow.Title
+	}
+	if len(flow.Define) > 0 && fd.Define == nil {
+		fd.Define = make(map[string]FlowPluginTypeDef)
+	}
+	for name, pluginType := range flow.Define {
+		fd.Define[name] = pluginType
+	}
+	fd.OnRepo = mergeTasks(fd.OnRepo, flow.OnRepo)
+	fd.OnForj = mergeTasks(fd.OnForj, flow.OnForj)
+}
+
+func mergeTasks(tasks, from map[string]FlowTaskDef) map[string]FlowTaskDef {
+	if len(from) > 0 && tasks == nil {
+		tasks = make(map[string]FlowTaskDef)
+	}
+	for name, task := range from {
+		tasks[name] = task
+	}
+	return tasks
+}
@@ -0,0 +1,94 @@
+package flow
+
+import (
+	"forjj/forjfile"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlowsLoadComposed(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "forjj-flows")
+	if !assert.NoError(err, "Expect a temporary directory") {
+		return
+	}
+	defer os.RemoveAll(dir)
+	writeFlow := func(name, data string) {
+		assert.NoError(os.MkdirAll(path.Join(dir, name), 0755))
+		assert.NoError(ioutil.WriteFile(path.Join(dir, name, name+".yaml"), []byte(data), 0644))
+	}
+	writeFlow("base", `title: Base flow
+on-repo-do:
+  ci:
+    description: base ci
+  doc:
+    description: base doc
+on-forjfile-do:
+  base-repo:
+    description: base repo
+    set:
+      repo:
+        myrepo:
+          title: Base title
+`)
+	writeFlow("security", `on-repo-do:
+  scan:
+    description: security scan
+`)
+	writeFlow("custom", `extends: base
+include: [security]
+on-repo-do:
+  ci:
+    description: custom ci
+`)
+	writeFlow("loop-a", "extends: loop-b\n")
+	writeFlow("loop-b", "include: [loop-a]\n")
+
+	fs := new(Flows)
+	fs.SetRepoPath(&url.URL{Path: dir})
+
+	/*************************************/
+	testCase := "when a flow extends and includes other flows"
+
+	if assert.NoErrorf(fs.Load("custom"), "Expect no error %s", testCase) {
+		flow := fs.all["custom"]
+		assert.Equalf("Base flow", flow.Title, "Expect the extended flow title %s", testCase)
+		assert.Lenf(flow.OnRepo, 3, "Expect tasks of all flows %s", testCase)
+		assert.Equalf("custom ci", flow.OnRepo["ci"].Description, "Expect the task to be overridden %s", testCase)
+		assert.Equalf("custom.yaml", flow.OnRepo["ci"].file, "Expect the overriding file %s", testCase)
+		assert.Equalf("base.yaml", flow.OnRepo["doc"].file, "Expect the extended flow file %s", testCase)
+		assert.Equalf("security.yaml", flow.OnRepo["scan"].file, "Expect the included flow file %s", testCase)
+	}
+
+	/*************************************/
+	testCase = "when a task of an extended flow is applied"
+
+	forge := forjfile.NewForgeYaml()
+	ffd := &forge.ForjCore
+	if assert.NoErrorf(fs.Apply("custom", nil, ffd), "Expect no error %s", testCase) {
+		history := ffd.GetSources("repo", "myrepo").History("title")
+		if assert.NotEmptyf(history, "Expect the title source %s", testCase) {
+			assert.Equalf("base.yaml", history[len(history)-1].File, "Expect the extended flow file %s", testCase)
+		}
+	}
+
+	/*************************************/
+	testCase = "when flows extend each other"
+
+	err = fs.Load("loop-a")
+	if assert.Errorf(err, "Expect an error %s", testCase) {
+		assert.Containsf(err.Error(), "loop-a -> loop-b -> loop-a", "Expect the cycle to be reported %s", testCase)
+	}
+
+	/*************************************/
+	testCase = "when an extended flow does not exist"
+
+	writeFlow("orphan", "extends: unknown\n")
+	assert.Errorf(fs.Check("orphan"), "Expect an error %s", testCase)
+}
@@ -0,0 +1,549 @@
+package flow
+
+import (
+	"fmt"
+	"forjj/forjfile"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// flowCondition is a parsed flow task `if` condition.
+//
+// Syntax:
+//
+//	condition  := or
+//	or         := and { "or" and }
+//	and        := not { "and" not }
+//	not        := "not" not | comparison
+//	comparison := operand [ ("==" | "!=" | "<" | "<=" | ">" | ">=" | "=~" | "!~") operand ]
+//	            | operand [ "not" ] "in" ( list | operand )
+//	operand    := 'string' | "string" | number | true | false | reference | list | "(" condition ")"
+//	list       := "[" [ operand { "," operand } ] "]"
+//	reference  := repo.<key> | deploy.name | deploy.type | deploy.pars.<name> | <object>.<instance>.<key>
+//
+// Ex: repo.role == 'code' and not deploy.type in ['DEV', 'TEST']
+type flowCondition struct {
+	source string
+	root   conditionNode
+}
+
+// conditionContext gives access to values referenced by a condition.
+type conditionContext struct {
+	repo     *forjfile.RepoStruct
+	forjfile *forjfile.DeployForgeYaml
+}
+
+// conditionNode is a condition element. Every value is a string. Booleans are 'true' or 'false'.
+type conditionNode interface {
+	eval(c *conditionContext) (string, error)
+	render(c *conditionContext) string // The node with references replaced by their values.
+}
+
+// parseCondition parses a condition. Errors are reported with the position of the issue.
+func parseCondition(source string) (ret *flowCondition, err error) {
+	p := conditionParser{source: source}
+	if p.tokens, err = tokenizeCondition(source); err != nil {
+		return nil, fmt.Errorf("Invalid condition '%s'. %s", source, err)
+	}
+	ret = &flowCondition{source: source}
+	if ret.root, err = p.parseOr(); err == nil && !p.eof() {
+		err = p.errorf("unexpected '%s'", p.peek().text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Invalid condition '%s'. %s", source, err)
+	}
+	return
+}
+
+// evaluate returns the condition result and the condition rendered with referenced values.
+func (fc *flowCondition) evaluate(repo *forjfile.RepoStruct, Forjfile *forjfile.DeployForgeYaml) (bool, string, error) {
+	c := &conditionContext{repo: repo, forjfile: Forjfile}
+	rendered := fc.root.render(c)
+	v, err := fc.root.eval(c)
+	if err != nil {
+		return false, rendered, fmt.Errorf("Unable to evaluate '%s'. %s", fc.source, err)
+	}
+	return conditionTrue(v), rendered, nil
+}
+
+// conditionTrue returns the boolean value of a condition value.
+// A value which is not a boolean is true if not empty.
+func conditionTrue(v string) bool {
+	if b, err := strconv.ParseBool(v); err == nil {
+		return b
+	}
+	return v != ""
+}
+
+func conditionBool(b bool) string {
+	return strconv.FormatBool(b)
+}
+
+/*************************************/
+// Tokenizer
+
+const (
+	tokenWord = iota
+	tokenString
+	tokenSymbol
+)
+
+type conditionToken struct {
+	kind int
+	text string
+	pos  int
+}
+
+var conditionSymbols = []string{"==", "!=", "<=", ">=", "=~", "!~", "<", ">", "(", ")", "[", "]", ","}
+
+func tokenizeCondition(source string) (tokens []conditionToken, _ error) {
+	for pos := 0; pos < len(source); {
+		char := source[pos]
+		switch {
+		case char == ' ' || char == '\t' || char == '\n' || char == '\r':
+			pos++
+			continue
+		case char == '\'' || char == '"':
+			end := strings.IndexByte(source[pos+1:], char)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string at position %d", pos+1)
+			}
+			tokens = append(tokens, conditionToken{tokenString, source[pos+1 : pos+1+end], pos})
+			pos += end + 2
+			continue
+		case isConditionWordChar(char):
+			end := pos
+			for end < len(source) && isConditionWordChar(source[end]) {
+				end++
+			}
+			tokens = append(tokens, conditionToken{tokenWord, source[pos:end], pos})
+			pos = end
+			continue
+		}
+		found := false
+		for _, symbol := range conditionSymbols {
+			if strings.HasPrefix(source[pos:], symbol) {
+				tokens = append(tokens, conditionToken{tokenSymbol, symbol, pos})
+				pos += len(symbol)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unexpected '%c' at position %d", char, pos+1)
+		}
+	}
+	return
+}
+
+func isConditionWordChar(char byte) bool {
+	return char >= 'a' && char <= 'z' || char >= 'A' && char <= 'Z' || char >= '0' && char <= '9' ||
+		char == '_' || char == '-' || char == '.'
+}
+
+/*************************************/
+// Parser
+
+type conditionParser struct {
+	source string
+	tokens []conditionToken
+	pos    int
+}
+
+func (p *conditionParser) eof() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *conditionParser) peek() conditionToken {
+	if p.eof() {
+		return conditionToken{kind: tokenSymbol, pos: len(p.source)}
+	}
+	return p.tokens[p.pos]
+}
+
+// accept moves to the next token if the current one is the keyword or symbol given.
+func (p *conditionParser) accept(text string) bool {
+	if token := p.peek(); !p.eof() && token.kind != tokenString && token.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *conditionParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%s at position %d", fmt.Sprintf(format, args...), p.peek().pos+1)
+}
+
+func (p *conditionParser) parseOr() (conditionNode, error) {
+	left, err := p.parseAnd()
+	for err == nil && p.accept("or") {
+		var right conditionNode
+		if right, err = p.parseAnd(); err == nil {
+			left = &conditionLogical{op: "or", left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *conditionParser) parseAnd() (conditionNode, error) {
+	left, err := p.parseNot()
+	for err == nil && p.accept("and") {
+		var right conditionNode
+		if right, err = p.parseNot(); err == nil {
+			left = &conditionLogical{op: "and", left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *conditionParser) parseNot() (conditionNode, error) {
+	if p.accept("not") {
+		node, err := p.parseNot()
+		return &conditionNot{node: node}, err
+	}
+	return p.parseComparison()
+}
+
+func (p *conditionParser) parseComparison() (conditionNode, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">", "=~", "!~"} {
+		if !p.accept(op) {
+			continue
+		}
+		ret := &conditionComparison{op: op, left: left}
+		if ret.right, err = p.parseOperand(); err != nil {
+			return nil, err
+		}
+		if literal, ok := ret.right.(*conditionLiteral); ok && (op == "=~" || op == "!~") {
+			if ret.regexp, err = regexp.Compile(literal.value); err != nil {
+				return nil, fmt.Errorf("invalid regular expression '%s'. %s", literal.value, err)
+			}
+		}
+		return ret, nil
+	}
+	negate := false
+	if token := p.peek(); token.kind == tokenWord && token.text == "not" && p.pos+1 < len(p.tokens) && p.tokens[p.pos+1].text == "in" {
+		p.pos++
+		negate = true
+	}
+	if !p.accept("in") {
+		return left, nil
+	}
+	ret := &conditionIn{negate: negate, value: left}
+	if ret.list, err = p.parseOperand(); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (p *conditionParser) parseOperand() (conditionNode, error) {
+	if p.eof() {
+		return nil, p.errorf("missing value")
+	}
+	token := p.peek()
+	switch {
+	case token.kind == tokenString:
+		p.pos++
+		return &conditionLiteral{value: token.text, quoted: true}, nil
+	case p.accept("("):
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, p.errorf("missing ')'")
+		}
+		return &conditionGroup{node: node}, nil
+	case p.accept("["):
+		list := &conditionList{}
+		if p.accept("]") {
+			return list, nil
+		}
+		for {
+			item, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			list.items = append(list.items, item)
+			if p.accept("]") {
+				return list, nil
+			}
+			if !p.accept(",") {
+				return nil, p.errorf("missing ',' or ']'")
+			}
+		}
+	case token.kind == tokenWord:
+		switch token.text {
+		case "and", "or", "not", "in":
+			return nil, p.errorf("unexpected '%s'", token.text)
+		case "true", "false":
+			p.pos++
+			return &conditionLiteral{value: token.text}, nil
+		}
+		p.pos++
+		if _, err := strconv.ParseFloat(token.text, 64); err == nil {
+			return &conditionLiteral{value: token.text}, nil
+		}
+		ref, err := newConditionReference(token.text)
+		if err != nil {
+			p.pos--
+			return nil, p.errorf("%s", err)
+		}
+		return ref, nil
+	}
+	return nil, p.errorf("unexpected '%s'", token.text)
+}
+
+/*************************************/
+// Nodes
+
+type conditionLiteral struct {
+	value  string
+	quoted bool
+}
+
+func (n *conditionLiteral) eval(*conditionContext) (string, error) {
+	return n.value, nil
+}
+
+func (n *conditionLiteral) render(*conditionContext) string {
+	if n.quoted {
+		return "'" + n.value + "'"
+	}
+	return n.value
+}
+
+// conditionReference is a value of the Forjfile or of the deployment.
+type conditionReference struct {
+	path []string
+}
+
+func newConditionReference(text string) (*conditionReference, error) {
+	path := strings.Split(text, ".")
+	for _, element := range path {
+		if element == "" {
+			return nil, fmt.Errorf("invalid reference '%s'", text)
+		}
+	}
+	switch {
+	case path[0] == "repo" && len(path) == 2:
+	case path[0] == "deploy" && len(path) == 2 && (path[1] == "name" || path[1] == "type"):
+	case path[0] == "deploy" && len(path) == 3 && path[1] == "pars":
+	case path[0] != "repo" && path[0] != "deploy" && len(path) == 3:
+	default:
+		return nil, fmt.Errorf("unknown reference '%s'. Use repo.<key>, deploy.name, deploy.type, deploy.pars.<name> or <object>.<instance>.<key>", text)
+	}
+	return &conditionReference{path: path}, nil
+}
+
+func (n *conditionReference) eval(c *conditionContext) (string, error) {
+	switch n.path[0] {
+	case "repo":
+		if c.repo == nil {
+			return "", nil
+		}
+		v, _ := c.repo.GetString(n.path[1])
+		return v, nil
+	case "deploy":
+		deploy, found := c.forjfile.GetDeployment()
+		if !found {
+			return "", nil
+		}
+		switch n.path[1] {
+		case "name":
+			return deploy.Name(), nil
+		case "type":
+			return deploy.Type, nil
+		}
+		return deploy.Pars[n.path[2]], nil
+	}
+	if c.forjfile == nil {
+		return "", nil
+	}
+	v, _, _ := c.forjfile.GetString(n.path[0], n.path[1], n.path[2])
+	return v, nil
+}
+
+func (n *conditionReference) render(c *conditionContext) string {
+	v, _ := n.eval(c)
+	return "'" + v + "'"
+}
+
+type conditionList struct {
+	items []conditionNode
+}
+
+func (n *conditionList) eval(c *conditionContext) (string, error) {
+	values, err := n.values(c)
+	return strings.Join(values, ","), err
+}
+
+func (n *conditionList) values(c *conditionContext) (ret []string, _ error) {
+	ret = make([]string, len(n.items))
+	for index, item := range n.items {
+		v, err := item.eval(c)
+		if err != nil {
+			return nil, err
+		}
+		ret[index] = v
+	}
+	return
+}
+
+func (n *conditionList) render(c *conditionContext) string {
+	items := make([]string, len(n.items))
+	for index, item := range n.items {
+		items[index] = item.render(c)
+	}
+	return "[" + strings.Join(items, ", ") + "]"
+}
+
+type conditionGroup struct {
+	node conditionNode
+}
+
+func (n *conditionGroup) eval(c *conditionContext) (string, error) {
+	return n.node.eval(c)
+}
+
+func (n *conditionGroup) render(c *conditionContext) string {
+	return "(" + n.node.render(c) + ")"
+}
+
+type conditionNot struct {
+	node conditionNode
+}
+
+func (n *conditionNot) eval(c *conditionContext) (string, error) {
+	v, err := n.node.eval(c)
+	return conditionBool(!conditionTrue(v)), err
+}
+
+func (n *conditionNot) render(c *conditionContext) string {
+	return "not " + n.node.render(c)
+}
+
+type conditionLogical struct {
+	op          string // and, or
+	left, right conditionNode
+}
+
+func (n *conditionLogical) eval(c *conditionContext) (string, error) {
+	left, err := n.left.eval(c)
+	if err != nil {
+		return "", err
+	}
+	if conditionTrue(left) == (n.op == "or") {
+		return conditionBool(n.op == "or"), nil
+	}
+	right, err := n.right.eval(c)
+	return conditionBool(conditionTrue(right)), err
+}
+
+func (n *conditionLogical) render(c *conditionContext) string {
+	return n.left.render(c) + " " + n.op + " " + n.right.render(c)
+}
+
+type conditionComparison struct {
+	op          string
+	left, right conditionNode
+	regexp      *regexp.Regexp // Compiled at parse time if the expression is a string.
+}
+
+func (n *conditionComparison) eval(c *conditionContext) (string, error) {
+	left, err := n.left.eval(c)
+	if err != nil {
+		return "", err
+	}
+	right, err := n.right.eval(c)
+	if err != nil {
+		return "", err
+	}
+	switch n.op {
+	case "==":
+		return conditionBool(left == right), nil
+	case "!=":
+		return conditionBool(left != right), nil
+	case "=~", "!~":
+		re := n.regexp
+		if re == nil {
+			if re, err = regexp.Compile(right); err != nil {
+				return "", fmt.Errorf("invalid regular expression '%s'. %s", right, err)
+			}
+		}
+		return conditionBool(re.MatchString(left) == (n.op == "=~")), nil
+	}
+	// Numbers are compared as numbers. Otherwise, as strings.
+	cmp := strings.Compare(left, right)
+	if l, err := strconv.ParseFloat(left, 64); err == nil {
+		if r, err := strconv.ParseFloat(right, 64); err == nil {
+			switch {
+			case l < r:
+				cmp = -1
+			case l > r:
+				cmp = 1
+			default:
+				cmp = 0
+			}
+		}
+	}
+	switch n.op {
+	case "<":
+		return conditionBool(cmp < 0), nil
+	case "<=":
+		return conditionBool(cmp <= 0), nil
+	case ">":
+		return conditionBool(cmp > 0), nil
+	}
+	return conditionBool(cmp >= 0), nil
+}
+
+func (n *conditionComparison) render(c *conditionContext) string {
+	return n.left.render(c) + " " + n.op + " " + n.right.render(c)
+}
+
+// conditionIn checks if a value is in a list. If the list is not a list literal, it is a comma separated value.
+type conditionIn struct {
+	negate bool
+	value  conditionNode
+	list   conditionNode
+}
+
+func (n *conditionIn) eval(c *conditionContext) (string, error) {
+	value, err := n.value.eval(c)
+	if err != nil {
+		return "", err
+	}
+	var items []string
+	if list, ok := n.list.(*conditionList); ok {
+		items, err = list.values(c)
+	} else {
+		var v string
+		if v, err = n.list.eval(c); v != "" {
+			items = strings.Split(v, ",")
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	found := false
+	for _, item := range items {
+		if strings.TrimSpace(item) == value {
+			found = true
+			break
+		}
+	}
+	return conditionBool(found != n.negate), nil
+}
+
+func (n *conditionIn) render(c *conditionContext) string {
+	op := " in "
+	if n.negate {
+		op = " not in "
+	}
+	return n.value.render(c) + op + n.list.render(c)
+}
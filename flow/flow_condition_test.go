@@ -0,0 +1,142 @@
+package flow
+
+import (
+	"forjj/forjfile"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCondition(t *testing.T) {
+	assert := assert.New(t)
+
+	/*************************************/
+	testCase := "when conditions are invalid"
+
+	for _, condition := range []string{
+		"repo.role ==",
+		"repo.role == 'code' and",
+		"(repo.role == 'code'",
+		"repo.role in ['a', 'b'",
+		"repo.role == 'code",
+		"repo.role & 'code'",
+		"role == 'code'",
+		"deploy.unknown == 'x'",
+		"repo.role =~ '['",
+		"repo.role not 'code'",
+	} {
+		_, err := parseCondition(condition)
+		assert.Errorf(err, "Expect '%s' to be rejected %s", condition, testCase)
+	}
+
+	/*************************************/
+	testCase = "when conditions are evaluated"
+
+	forge := forjfile.NewForgeYaml()
+	ffd := &forge.ForjCore
+	ffd.Set("test", "app", "github", "type", "upstream")
+	ffd.Set("test", "repo", "myrepo", forjfile.FieldRepoTitle, "My repo")
+	ffd.Set("test", "repo", "myrepo", forjfile.FieldRepoRole, "code")
+	ffd.Set("test", "repo", "myrepo", "count", "10")
+	repo := ffd.Repos["myrepo"]
+
+	for condition, expected := range map[string]bool{
+		"repo.role == 'code'":                               true,
+		"repo.role == 'code' and not deploy.type == 'DEV'":  true,
+		"repo.role != 'code' or app.github.type == 'ci'":    false,
+		"not (repo.role == 'code' or repo.title == '')":     false,
+		"repo.title =~ '^My'":                               true,
+		"repo.title !~ 'repo$'":                             false,
+		"repo.role in ['infra', 'code']":                    true,
+		"repo.role not in [\"infra\", \"code\"]":            false,
+		"repo.count > 9 and repo.count <= 10":               true,
+		"repo.count < 9":                                    false,
+		"repo.title":                                        true,
+		"repo.unknown":                                      false,
+		"deploy.pars.region == ''":                          true,
+		"app.github.type in 'upstream,ci' and true":         true,
+		"false or repo.role == 'code' and repo.title == ''": false,
+	} {
+		c, err := parseCondition(condition)
+		if !assert.NoErrorf(err, "Expect '%s' to be valid %s", condition, testCase) {
+			continue
+		}
+		result, _, err := c.evaluate(repo, ffd)
+		assert.NoErrorf(err, "Expect '%s' to be evaluated %s", condition, testCase)
+		assert.Equalf(expected, result, "Expect '%s' to be %t %s", condition, expected, testCase)
+	}
+
+	/*************************************/
+	testCase = "when a condition is rendered"
+
+	c, _ := parseCondition("repo.role in ['infra', 'code'] and not repo.title == 'x'")
+	_, rendered, _ := c.evaluate(repo, ffd)
+	assert.Equalf("'code' in ['infra', 'code'] and not 'My repo' == 'x'", rendered, "Expect references to be replaced %s", testCase)
+}
+
+func TestConditionOnDeployment(t *testing.T) {
+	assert := assert.New(t)
+
+	infraPath, err := ioutil.TempDir("", "forjj-condition")
+	if !assert.NoError(err, "Expect temporary directory to be created") {
+		return
+	}
+	defer os.RemoveAll(infraPath)
+	write := func(file, data string) {
+		file = path.Join(infraPath, file)
+		os.MkdirAll(path.Dir(file), 0755)
+		assert.NoError(ioutil.WriteFile(file, []byte(data), 0644), "Expect file %s to be written", file)
+	}
+	write("Forjfile", `deployments:
+  dev:
+    type: DEV
+    parameters:
+      region: eu
+  production:
+    type: PRO
+repositories:
+  myrepo:
+    title: My repo
+    role: code
+`)
+	write("deployments/dev/Forjfile", "")
+	write("deployments/production/Forjfile", "")
+
+	f := new(forjfile.Forge)
+	assert.NoError(f.SetInfraPath(infraPath, true), "Expect infra path to be set")
+	if _, err = f.Load("dev"); !assert.NoError(err, "Expect Forjfile to be loaded") {
+		return
+	}
+
+	for deploy, conditions := range map[string]map[string]bool{
+		"dev": {
+			"repo.role == 'code' and not deploy.type in ['DEV', 'TEST']": false,
+			"deploy.name == 'dev' and deploy.type == 'DEV'":              true,
+			"deploy.pars.region == 'eu'":                                 true,
+		},
+		"production": {
+			"repo.role == 'code' and not deploy.type in ['DEV', 'TEST']": true,
+			"deploy.pars.region == 'eu'":                                 false,
+		},
+	} {
+		/*************************************/
+		testCase := "when conditions are evaluated on the '" + deploy + "' deployment"
+
+		ffd, err := f.MergeFromDeployment(deploy)
+		if !assert.NoErrorf(err, "Expect the deployment to be merged %s", testCase) {
+			continue
+		}
+		for condition, expected := range conditions {
+			c, err := parseCondition(condition)
+			if !assert.NoErrorf(err, "Expect '%s' to be valid %s", condition, testCase) {
+				continue
+			}
+			result, _, err := c.evaluate(ffd.Repos["myrepo"], ffd)
+			assert.NoErrorf(err, "Expect '%s' to be evaluated %s", condition, testCase)
+			assert.Equalf(expected, result, "Expect '%s' to be %t %s", condition, expected, testCase)
+		}
+	}
+}
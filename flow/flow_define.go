@@ -4,19 +4,25 @@ import (
 	"fmt"
 	"forjj/forjfile"
 	"forjj/utils"
+	"sort"
 
 	"github.com/forj-oss/forjj-modules/trace"
 )
 
 type FlowDefine struct { // Yaml structure
-	Name   string
-	Title  string // Flow title
-	Define map[string]FlowPluginTypeDef
-	OnRepo map[string]FlowTaskDef `yaml:"on-repo-do"`
-	OnForj map[string]FlowTaskDef `yaml:"on-forjfile-do"`
+	Name    string
+	Title   string   // Flow title
+	Extends string   // Flow which defines tasks and plugins types overridden by this flow.
+	Include []string // Flows which tasks and plugins types are added, after Extends.
+	Define  map[string]FlowPluginTypeDef
+	OnRepo  map[string]FlowTaskDef `yaml:"on-repo-do"`
+	OnForj  map[string]FlowTaskDef `yaml:"on-forjfile-do"`
+	file    string                 // Flow file loaded.
 }
 
-func (fd *FlowDefine) apply(repo *forjfile.RepoStruct, Forjfile *forjfile.DeployForgeYaml) error {
+// apply runs the flow tasks on the Forjfile (repo is nil) or on a repository.
+// If trace is set, it records the evaluation of each task and values set.
+func (fd *FlowDefine) apply(repo *forjfile.RepoStruct, Forjfile *forjfile.DeployForgeYaml, trace *FlowTrace) error {
 	bInError := false
 
 	var tasks map[string]FlowTaskDef
@@ -26,19 +32,27 @@ func (fd *FlowDefine) apply(repo *forjfile.RepoStruct, Forjfile *forjfile.Deploy
 		tasks = fd.OnRepo
 	}
 
-	for _, flowTask := range tasks {
+	// Tasks are applied in name order.
+	tasksName := make([]string, 0, len(tasks))
+	for name := range tasks {
+		tasksName = append(tasksName, name)
+	}
+	sort.Strings(tasksName)
+
+	for _, taskName := range tasksName {
+		flowTask := tasks[taskName]
 		onWhat := "Forjfile"
 		if repo != nil {
 			name, _ := repo.GetString("name")
 			onWhat = fmt.Sprintf("repository '%s'", name)
-		} else {
-
 		}
 		gotrace.Trace("flow '%s': %s on %s is being checked.\n---", fd.Name, flowTask.Description, onWhat)
+		taskTrace := trace.addTask(taskName, flowTask.Description, flowTask.file)
 
-		task_to_set, err := flowTask.if_section(repo, Forjfile)
+		task_to_set, err := flowTask.if_section(repo, Forjfile, taskTrace)
 		if err != nil {
 			gotrace.Error("Flow '%s' - if section: Unable to apply flow task '%s'.", fd.Name, err)
+			taskTrace.setError(err)
 			bInError = true
 			continue
 		}
@@ -47,14 +61,19 @@ func (fd *FlowDefine) apply(repo *forjfile.RepoStruct, Forjfile *forjfile.Deploy
 			gotrace.Trace("Flow task not applied to %s. The 'if' condition fails.\n---", onWhat)
 			continue
 		}
+		if taskTrace != nil {
+			taskTrace.Applied = true
+		}
 
 		gotrace.Trace("'%s' flow task \"%s\" applying to %s.", fd.Name, flowTask.Description, onWhat)
 
 		tmpl_data := New_FlowTaskModel(repo, Forjfile)
 
 		if flowTask.List == nil {
-			if err := flowTask.Set.apply(tmpl_data, Forjfile); err != nil {
+			iteration := taskTrace.addIteration(nil)
+			if err := flowTask.Set.apply(flowTask.file, flowTask.lines, tmpl_data, Forjfile, iteration); err != nil {
 				gotrace.Error("Unable to apply '%s' flow task '%s' on %s. %s", fd.Name, flowTask.Description, onWhat, err)
+				iteration.setError(err)
 				continue
 			}
 			gotrace.Trace("'%s' flow task '%s' applied on %s.\n---", fd.Name, flowTask.Description, onWhat)
@@ -64,9 +83,22 @@ func (fd *FlowDefine) apply(repo *forjfile.RepoStruct, Forjfile *forjfile.Deploy
 		// Load list
 		max := make([]int, len(flowTask.List))
 
+		listInError := false
 		for index, taskList := range flowTask.List {
-			taskList.list = taskList.Get(repo, Forjfile)
+			list, err := taskList.Get(repo, Forjfile)
+			if err != nil {
+				gotrace.Error("Unable to load '%s' flow task '%s' list '%s' on %s. %s", fd.Name, flowTask.Description, taskList.Name, onWhat, err)
+				taskTrace.setError(err)
+				listInError = true
+				break
+			}
+			taskList.list = list
 			max[index] = len(taskList.list)
+			taskTrace.addList(taskList)
+		}
+		if listInError {
+			bInError = true
+			continue
 		}
 
 		// Loop on list and set CurrentList
@@ -78,8 +110,10 @@ func (fd *FlowDefine) apply(repo *forjfile.RepoStruct, Forjfile *forjfile.Deploy
 				tmpl_data.List[flowTaskList.Name] = flowTaskList.list[pos]
 			}
 
-			if err := flowTask.Set.apply(tmpl_data, Forjfile); err != nil {
+			iteration := taskTrace.addIteration(tmpl_data.List)
+			if err := flowTask.Set.apply(flowTask.file, flowTask.lines, tmpl_data, Forjfile, iteration); err != nil {
 				gotrace.Error("Unable to apply flow task '%s' on %s. %s", fd.Name, onWhat, err)
+				iteration.setError(err)
 			} else {
 				gotrace.Trace("'%s' flow task '%s' applied on %s.\n---", fd.Name, flowTask.Description, onWhat)
 			}
@@ -94,11 +128,33 @@ func (fd *FlowDefine) apply(repo *forjfile.RepoStruct, Forjfile *forjfile.Deploy
 	return nil
 }
 
-func (ftd *FlowTaskDef) if_section(repo *forjfile.RepoStruct, Forjfile *forjfile.DeployForgeYaml) (task_to_set bool, _ error) {
+// compile parses each task `if` conditions and checks `loop-on-list` lists.
+// See FlowTaskIf.compile and FlowTaskList.compile
+func (fd *FlowDefine) compile() error {
+	for _, tasks := range []map[string]FlowTaskDef{fd.OnForj, fd.OnRepo} {
+		for name, task := range tasks {
+			for index := range task.If {
+				if err := task.If[index].compile(); err != nil {
+					return fmt.Errorf("Task '%s': %s", name, err)
+				}
+			}
+			for _, list := range task.List {
+				if err := list.compile(); err != nil {
+					return fmt.Errorf("Task '%s': %s", name, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (ftd *FlowTaskDef) if_section(repo *forjfile.RepoStruct, Forjfile *forjfile.DeployForgeYaml, trace *FlowTaskTrace) (task_to_set bool, _ error) {
 	task_to_set = true
 	if ftd.If != nil {
 		for _, ftif := range ftd.If {
-			if v, err := ftif.IfEvaluate(repo, Forjfile); err != nil {
+			v, rendered, err := ftif.evaluate(repo, Forjfile)
+			trace.addIf(ftif.String(), rendered, v, err)
+			if err != nil {
 				return false, err
 			} else if !v {
 				task_to_set = false
@@ -6,47 +6,101 @@ import (
 	"fmt"
 	"bytes"
 	"strconv"
+	"sort"
 	"strings"
 	"github.com/forj-oss/forjj-modules/trace"
 )
 
 type FlowTaskIf struct {
-	Rule string
-	List map[string]string `yaml:",inline"`
+	Rule      string
+	Condition string            // See flow_condition.go
+	List      map[string]string `yaml:",inline"`
+	condition *flowCondition    // Condition parsed at flow load time.
+}
+
+// compile parses the condition and the rule template, to report syntax errors at flow load time.
+func (fti *FlowTaskIf) compile() (err error) {
+	if fti.Condition != "" {
+		fti.condition, err = parseCondition(fti.Condition)
+		return
+	}
+	if fti.Rule != "" {
+		// Functions are only declared to parse the rule. No Forjfile is required.
+		var noForjfile *forjfile.DeployForgeYaml
+		if _, err = template.New("flow-eval").Funcs(noForjfile.TemplateFuncs()).Parse(fti.Rule); err != nil {
+			return fmt.Errorf("Error in template '%s'. %s", fti.Rule, err)
+		}
+	}
+	return
 }
 
 // IfEvaluate will interpret
 func (fti *FlowTaskIf)IfEvaluate(repo *forjfile.RepoStruct, Forjfile *forjfile.DeployForgeYaml) (_ bool, _ error) {
+	result, _, err := fti.evaluate(repo, Forjfile)
+	return result, err
+}
+
+// evaluate returns the rule result and the rendered rule.
+func (fti *FlowTaskIf) evaluate(repo *forjfile.RepoStruct, Forjfile *forjfile.DeployForgeYaml) (_ bool, rendered string, _ error) {
+	if fti.Condition != "" {
+		if fti.condition == nil {
+			if err := fti.compile(); err != nil {
+				return false, "", err
+			}
+		}
+		result, rendered, err := fti.condition.evaluate(repo, Forjfile)
+		gotrace.Trace("'%s' evaluated to '%s': %t", fti.Condition, rendered, result)
+		return result, rendered, err
+	}
 	if fti.Rule != "" {
 		var doc bytes.Buffer
 
-		if t, err:= template.New("flow-eval").Funcs(template.FuncMap{
-				}).Parse(fti.Rule); err != nil {
-			return false, fmt.Errorf("Error in template evaluation. %s", err)
+		if t, err:= template.New("flow-eval").Funcs(Forjfile.TemplateFuncs()).Parse(fti.Rule); err != nil {
+			return false, "", fmt.Errorf("Error in template evaluation. %s", err)
 		} else {
 			if err = t.Execute(&doc, New_FlowTaskModel(repo, Forjfile)) ; err != nil {
-				return false, fmt.Errorf("Unable to evaluate '%s'. %s", fti.Rule, err)
+				return false, "", fmt.Errorf("Unable to evaluate '%s'. %s", fti.Rule, err)
 			}
 		}
 
-		result := doc.String()
-		gotrace.Trace("'%s' evaluated to '%s'", fti.Rule, result)
-		switch strings.ToLower(result) {
+		rendered = doc.String()
+		gotrace.Trace("'%s' evaluated to '%s'", fti.Rule, rendered)
+		switch strings.ToLower(rendered) {
 		case "", "not found" :
 			return
 		case "found" :
-			return true, nil
+			return true, rendered, nil
 		default:
-			return strconv.ParseBool(result)
+			result, err := strconv.ParseBool(rendered)
+			return result, rendered, err
 		}
 	}
 
 	if fti.List != nil {
-		rules := make([]string, 0, len(fti.List))
-		for key, value := range fti.List {
-			rules = append(rules, key + ":" + value)
-		}
-		return repo.HasValues(rules ...)
+		rules := fti.rules()
+		result, err := repo.HasValues(rules ...)
+		return result, strings.Join(rules, ", "), err
+	}
+	return true, "", nil
+}
+
+// String returns the rule as defined in the flow.
+func (fti *FlowTaskIf) String() string {
+	if fti.Condition != "" {
+		return fti.Condition
+	}
+	if fti.Rule != "" {
+		return fti.Rule
+	}
+	return strings.Join(fti.rules(), ", ")
+}
+
+// rules returns the list of '<key>:<value>' rules, sorted.
+func (fti *FlowTaskIf) rules() (rules []string) {
+	rules = make([]string, 0, len(fti.List))
+	for key, value := range fti.List {
+		rules = append(rules, key + ":" + value)
 	}
-	return true, nil
+	sort.Strings(rules)
+	return
 }
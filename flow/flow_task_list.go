@@ -1,6 +1,10 @@
 package flow
 
-import "forjj/forjfile"
+import (
+	"fmt"
+	"forjj/forjfile"
+	"sort"
+)
 
 type FlowTaskLists []*FlowTaskList
 
@@ -11,20 +15,115 @@ type FlowTaskList struct {
 	list []interface{}
 }
 
-func (ftl *FlowTaskList)Get(repo *forjfile.RepoStruct, _ *forjfile.DeployForgeYaml) (list []interface{}) {
+// Lists supported by `loop-on-list`. Parameters are '<key>:<value>' filters, unless documented.
+const (
+	listApps         = "GetApps"         // Applications connected to the repository.
+	listRepos        = "GetRepos"        // Repositories.
+	listUsers        = "GetUsers"        // Users.
+	listGroups       = "GetGroups"       // Groups.
+	listGroupMembers = "GetGroupMembers" // Members of each group. Parameters filter groups.
+	listDeployments  = "GetDeployments"  // Deployments. Ex: type:PRO
+	listObjects      = "GetObjects"      // Plugin object instances. The first parameter is the object name.
+)
+
+var flowTaskLists = []string{listApps, listRepos, listUsers, listGroups, listGroupMembers, listDeployments, listObjects}
+
+// compile checks the list definition, to report errors at flow load time.
+func (ftl *FlowTaskList)compile() error {
+	found := false
+	for _, list := range flowTaskLists {
+		if ftl.List == list {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("loop-on-list '%s': unknown list '%s'. Supported: %s", ftl.Name, ftl.List, flowTaskLists)
+	}
+	if ftl.List == listObjects && len(ftl.Parameters) == 0 {
+		return fmt.Errorf("loop-on-list '%s': %s requires the object name as first parameter", ftl.Name, listObjects)
+	}
+	return nil
+}
+
+// Get returns the list items, in name order. Each item is a model with a Get(key) function.
+func (ftl *FlowTaskList)Get(repo *forjfile.RepoStruct, Forjfile *forjfile.DeployForgeYaml) (list []interface{}, _ error) {
 	list = []interface{}{}
 
 	switch ftl.List {
-	case "GetApps":
+	case listApps:
+		if repo == nil {
+			return nil, fmt.Errorf("%s is available only on repositories", listApps)
+		}
 		if ftl.Parameters == nil {
 			ftl.Parameters = []string{}
 		}
 		if apps , err := repo.GetApps(ftl.Parameters...) ; err == nil {
+			names := make([]string, 0, len(apps))
+			for name := range apps {
+				names = append(names, name)
+			}
+			sort.Strings(names)
 			list = make([]interface{}, 0, len(apps))
-			for _, app := range apps {
-				list = append(list, app.Model())
+			for _, name := range names {
+				list = append(list, apps[name].Model())
 			}
 		}
+	case listRepos:
+		repos, err := Forjfile.FilterInstances("repo", ftl.Parameters...)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range repos {
+			list = append(list, Forjfile.Repos[name].Model())
+		}
+	case listUsers, listGroups:
+		object := "user"
+		if ftl.List == listGroups {
+			object = "group"
+		}
+		instances, err := Forjfile.FilterInstances(object, ftl.Parameters...)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range instances {
+			list = append(list, Forjfile.ObjectModel(object, name))
+		}
+	case listGroupMembers:
+		groups, err := Forjfile.FilterInstances("group", ftl.Parameters...)
+		if err != nil {
+			return nil, err
+		}
+		for _, group := range groups {
+			for _, member := range Forjfile.Groups[group].GetMembers() {
+				list = append(list, forjfile.GroupMemberModel{
+					Group: Forjfile.ObjectModel("group", group),
+					User:  Forjfile.ObjectModel("user", member),
+				})
+			}
+		}
+	case listDeployments:
+		deploys, err := Forjfile.FilterDeployments(ftl.Parameters...)
+		if err != nil {
+			return nil, err
+		}
+		for _, deploy := range deploys {
+			list = append(list, deploy.Model())
+		}
+	case listObjects:
+		if len(ftl.Parameters) == 0 {
+			return nil, fmt.Errorf("%s requires the object name as first parameter", listObjects)
+		}
+		object := ftl.Parameters[0]
+		instances, err := Forjfile.FilterInstances(object, ftl.Parameters[1:]...)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range instances {
+			list = append(list, Forjfile.ObjectModel(object, name))
+		}
+	default:
+		return nil, fmt.Errorf("Unknown list '%s'", ftl.List)
 	}
 	return
 }
@@ -11,13 +11,16 @@ import (
 
 type FlowTaskSet map[string]map[string]forjfile.ForjValues
 
-func (fts FlowTaskSet) apply(tmpl_data *FlowTaskModel, Forjfile *forjfile.DeployForgeYaml) error {
+// apply sets the Forjfile values defined by the flow task.
+// Each value is recorded as set by the flow, defined in the flow file at the line given by lines.
+// (by object/instance/key, as written in the flow file. See setTasksFile)
+// If trace is set, values set are recorded in it.
+func (fts FlowTaskSet) apply(flowFile string, lines map[string]int, tmpl_data *FlowTaskModel, Forjfile *forjfile.DeployForgeYaml, trace *FlowIterationTrace) error {
 	tmpl := template.New("flow-set")
-	funcs := template.FuncMap{
-		"concatenate": fmt.Sprint,
-	}
+	funcs := Forjfile.TemplateFuncs()
 	for object_name, object_data := range fts {
-		for instance_name, instance_data := range object_data {
+		for instance_key, instance_data := range object_data {
+			instance_name := instance_key
 			if v, err := utils.Evaluate(instance_name, tmpl, tmpl_data, funcs); err != nil {
 				return fmt.Errorf("Unable to evaluate instance '%s'. %s", instance_name, err)
 			} else {
@@ -25,10 +28,12 @@ func (fts FlowTaskSet) apply(tmpl_data *FlowTaskModel, Forjfile *forjfile.Deploy
 			}
 			if len(instance_data) == 0 {
 				Forjfile.Set("", object_name, instance_name, "", "")
+				trace.addChange(object_name, instance_name, "", "")
 				gotrace.Trace("'%s/%s: {}' added.", object_name, instance_name)
 				continue
 			}
-			for key, value := range instance_data {
+			for value_key, value := range instance_data {
+				key := value_key
 				if v, err := utils.Evaluate(key, tmpl, tmpl_data, funcs); err != nil {
 					return fmt.Errorf("Unable to evaluate instance key '%s'. %s", instance_name, err)
 				} else {
@@ -41,6 +46,8 @@ func (fts FlowTaskSet) apply(tmpl_data *FlowTaskModel, Forjfile *forjfile.Deploy
 						gotrace.Trace("'%s' has be interpreted as '%s'.", ev, v)
 					}
 					Forjfile.Set("flow", object_name, instance_name, key, v)
+					trace.addChange(object_name, instance_name, key, v)
+					Forjfile.GetSources(object_name, instance_name).SetLocation(key, flowFile, lines[object_name+"/"+instance_key+"/"+value_key])
 					if v == "" {
 						gotrace.Trace("'%s/%s: {}' added. '%s/%s/%s' deleted.",
 							object_name, instance_name, object_name, instance_name, key)
@@ -0,0 +1,168 @@
+package flow
+
+import (
+	"fmt"
+	"sort"
+)
+
+// FlowTrace records what a flow did on the Forjfile or on a repository. See Flows.StartTrace
+type FlowTrace struct {
+	Flow  string           `json:"flow"`
+	File  string           `json:"file,omitempty"`
+	Repo  string           `json:"repo,omitempty"` // Empty when the flow is applied on the Forjfile.
+	Tasks []*FlowTaskTrace `json:"tasks"`
+	Error string           `json:"error,omitempty"`
+}
+
+// FlowTaskTrace records a flow task evaluation and the values it set.
+type FlowTaskTrace struct {
+	Name        string                `json:"name"`
+	Description string                `json:"description,omitempty"`
+	File        string                `json:"file,omitempty"` // Flow file which defines the task.
+	If          []FlowIfTrace         `json:"if,omitempty"`
+	Applied     bool                  `json:"applied"`
+	Lists       []FlowListTrace       `json:"loop-on-list,omitempty"`
+	Iterations  []*FlowIterationTrace `json:"iterations,omitempty"`
+	Error       string                `json:"error,omitempty"`
+}
+
+// FlowIfTrace is an `if` rule with its rendered value.
+type FlowIfTrace struct {
+	Rule     string `json:"rule"`
+	Rendered string `json:"rendered"`
+	Result   bool   `json:"result"`
+	Error    string `json:"error,omitempty"`
+}
+
+// FlowListTrace is a `loop-on-list` list with its items.
+type FlowListTrace struct {
+	Name       string   `json:"name"`
+	List       string   `json:"list"`
+	Parameters []string `json:"parameters,omitempty"`
+	Items      []string `json:"items"`
+}
+
+// FlowIterationTrace records values set by a task, for one item of each list.
+type FlowIterationTrace struct {
+	Items   map[string]string `json:"items,omitempty"` // Item of each list, by list name.
+	Changes []FlowChange      `json:"changes"`
+	Error   string            `json:"error,omitempty"`
+}
+
+// FlowChange is a Forjfile value set or deleted by a flow task.
+type FlowChange struct {
+	Object   string `json:"object"`
+	Instance string `json:"instance"`
+	Key      string `json:"key,omitempty"` // Empty when the instance is only added.
+	Value    string `json:"value,omitempty"`
+	Deleted  bool   `json:"deleted,omitempty"`
+}
+
+func (t *FlowTrace) addTask(name, description, file string) *FlowTaskTrace {
+	if t == nil {
+		return nil
+	}
+	task := &FlowTaskTrace{Name: name, Description: description, File: file}
+	t.Tasks = append(t.Tasks, task)
+	return task
+}
+
+func (t *FlowTaskTrace) addIf(rule, rendered string, result bool, err error) {
+	if t == nil {
+		return
+	}
+	ifTrace := FlowIfTrace{Rule: rule, Rendered: rendered, Result: result}
+	if err != nil {
+		ifTrace.Error = err.Error()
+	}
+	t.If = append(t.If, ifTrace)
+}
+
+func (t *FlowTaskTrace) addList(list *FlowTaskList) {
+	if t == nil {
+		return
+	}
+	listTrace := FlowListTrace{
+		Name:       list.Name,
+		List:       list.List,
+		Parameters: list.Parameters,
+		Items:      make([]string, len(list.list)),
+	}
+	for index, item := range list.list {
+		listTrace.Items[index] = describeListItem(item)
+	}
+	t.Lists = append(t.Lists, listTrace)
+}
+
+// addIteration starts the record of values set. `items` is the item of each list, by list name.
+func (t *FlowTaskTrace) addIteration(items map[string]interface{}) *FlowIterationTrace {
+	if t == nil {
+		return nil
+	}
+	iteration := &FlowIterationTrace{Changes: []FlowChange{}}
+	if len(items) > 0 {
+		iteration.Items = make(map[string]string)
+		for name, item := range items {
+			iteration.Items[name] = describeListItem(item)
+		}
+	}
+	t.Iterations = append(t.Iterations, iteration)
+	return iteration
+}
+
+func (t *FlowTaskTrace) setError(err error) {
+	if t == nil || err == nil {
+		return
+	}
+	t.Error = err.Error()
+}
+
+func (i *FlowIterationTrace) addChange(object, instance, key, value string) {
+	if i == nil {
+		return
+	}
+	i.Changes = append(i.Changes, FlowChange{
+		Object:   object,
+		Instance: instance,
+		Key:      key,
+		Value:    value,
+		Deleted:  key != "" && value == "",
+	})
+}
+
+func (i *FlowIterationTrace) setError(err error) {
+	if i == nil || err == nil {
+		return
+	}
+	i.Error = err.Error()
+}
+
+// sortChanges sorts values set by each task iteration, by object, instance and key.
+func (t *FlowTrace) sortChanges() {
+	for _, task := range t.Tasks {
+		for _, iteration := range task.Iterations {
+			changes := iteration.Changes
+			sort.Slice(changes, func(i, j int) bool {
+				if changes[i].Object != changes[j].Object {
+					return changes[i].Object < changes[j].Object
+				}
+				if changes[i].Instance != changes[j].Instance {
+					return changes[i].Instance < changes[j].Instance
+				}
+				return changes[i].Key < changes[j].Key
+			})
+		}
+	}
+}
+
+// describeListItem returns the name of a list item. Ex: an application name for GetApps.
+func describeListItem(item interface{}) string {
+	if v, ok := item.(interface {
+		Get(string) string
+	}); ok {
+		if name := v.Get("name"); name != "" {
+			return name
+		}
+	}
+	return fmt.Sprint(item)
+}
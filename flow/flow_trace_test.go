@@ -0,0 +1,65 @@
+package flow
+
+import (
+	"forjj/forjfile"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlowDefineApplyTrace(t *testing.T) {
+	assert := assert.New(t)
+
+	forge := forjfile.NewForgeYaml()
+	ffd := &forge.ForjCore
+	ffd.Set("test", "app", "github", "type", "upstream")
+
+	fd := &FlowDefine{
+		Name: "test",
+		file: "test.yaml",
+		OnForj: map[string]FlowTaskDef{
+			"b-skipped": {
+				Description: "never applied",
+				If:          []FlowTaskIf{{Rule: "false"}},
+				Set:         FlowTaskSet{"repo": {"never": {"title": forjfile.ForjValue{}}}},
+			},
+			"a-applied": {
+				Description: "always applied",
+				If:          []FlowTaskIf{{Rule: "{{ \"true\" }}"}},
+				Set: FlowTaskSet{"repo": {"{{ \"myrepo\" }}": {
+					"title": newForjValue("My repo"),
+					"flow":  newForjValue(""),
+				}}},
+			},
+		},
+	}
+
+	/*************************************/
+	testCase := "when a flow is applied on the Forjfile with a trace"
+
+	trace := &FlowTrace{Flow: fd.Name}
+	assert.NoErrorf(fd.apply(nil, ffd, trace), "Expect no error %s", testCase)
+	if assert.Lenf(trace.Tasks, 2, "Expect 2 tasks %s", testCase) {
+		applied := trace.Tasks[0]
+		assert.Equalf("a-applied", applied.Name, "Expect tasks in name order %s", testCase)
+		assert.Truef(applied.Applied, "Expect the task to be applied %s", testCase)
+		assert.Equalf([]FlowIfTrace{{Rule: "{{ \"true\" }}", Rendered: "true", Result: true}}, applied.If, "Expect the rendered rule %s", testCase)
+		if assert.Lenf(applied.Iterations, 1, "Expect 1 iteration %s", testCase) {
+			trace.sortChanges()
+			assert.Equalf([]FlowChange{
+				{Object: "repo", Instance: "myrepo", Key: "flow", Deleted: true},
+				{Object: "repo", Instance: "myrepo", Key: "title", Value: "My repo"},
+			}, applied.Iterations[0].Changes, "Expect values set and deleted %s", testCase)
+		}
+
+		skipped := trace.Tasks[1]
+		assert.Falsef(skipped.Applied, "Expect the task to be skipped %s", testCase)
+		assert.Emptyf(skipped.Iterations, "Expect nothing set %s", testCase)
+	}
+	assert.Equalf("My repo", ffd.Repos["myrepo"].Title, "Expect the Forjfile to be updated %s", testCase)
+}
+
+func newForjValue(value string) (ret forjfile.ForjValue) {
+	ret.Set(value)
+	return
+}
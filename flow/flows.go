@@ -8,11 +8,13 @@ import (
 
 	"github.com/forj-oss/forjj-modules/trace"
 	"gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
 )
 
 type Flows struct {
-	all   map[string]*FlowDefine
-	paths []*url.URL
+	all    map[string]*FlowDefine
+	paths  []*url.URL
+	traces []*FlowTrace // Flows applied since StartTrace. nil if not tracing.
 }
 
 // Load flow the first flow file found.
@@ -32,21 +34,61 @@ func (fs *Flows) Load(flows ...string) error {
 	return nil
 }
 
-func (fs *Flows) loadFlow(flowName string) (flow *FlowDefine, _ error) {
+// loadFlow loads a flow with flows it extends or includes. See composeFlow
+func (fs *Flows) loadFlow(flowName string) (*FlowDefine, error) {
+	return fs.composeFlow(flowName, nil)
+}
+
+// readFlow loads a flow file.
+func (fs *Flows) readFlow(flowName string) (flow *FlowDefine, _ error) {
 	if data, err := utils.ReadDocumentFrom(fs.paths, []string{""}, []string{flowName}, flowName+".yaml", ""); err == nil {
 		flow = new(FlowDefine)
 		if err = yaml.Unmarshal(data, flow); err != nil {
 			return nil, fmt.Errorf("Unable to load the flow '%s'. %s", flowName, err)
 		}
+		if err = flow.compile(); err != nil {
+			return nil, fmt.Errorf("Unable to load the flow '%s'. %s", flowName, err)
+		}
 		if flow.Name == "" {
 			flow.Name = flowName
 		}
+		flow.file = flowName + ".yaml"
+		var doc yamlv3.Node
+		yamlv3.Unmarshal(data, &doc) // Already loaded. Used only to get lines.
+		setTasksFile(flow.OnRepo, flow.file, &doc, "on-repo-do")
+		setTasksFile(flow.OnForj, flow.file, &doc, "on-forjfile-do")
 	} else {
 		return nil, fmt.Errorf("Unable to find '%s'. %s", flowName, err)
 	}
 	return flow, nil
 }
 
+// setTasksFile sets the file which defines each task, and the line of each value set by the task.
+// `section` is the flow document section of the tasks.
+func setTasksFile(tasks map[string]FlowTaskDef, file string, doc *yamlv3.Node, section string) {
+	for name, task := range tasks {
+		task.file = file
+		task.lines = make(map[string]int)
+		for object, instances := range task.Set {
+			for instance, values := range instances {
+				for key := range values {
+					task.lines[object+"/"+instance+"/"+key] = utils.YamlLine(doc, section, name, "set", object, instance, key)
+				}
+			}
+		}
+		tasks[name] = task
+	}
+}
+
+// Check returns an error if the flow cannot be loaded. Flows already loaded are not read again.
+func (fs *Flows) Check(flowName string) error {
+	if _, found := fs.all[flowName]; found {
+		return nil
+	}
+	_, err := fs.loadFlow(flowName)
+	return err
+}
+
 // SetRepoPath set the collection of repositories in the flows object.
 func (fs *Flows) SetRepoPath(paths ...*url.URL) {
 	if fs == nil {
@@ -92,5 +134,37 @@ func (fs *Flows) Apply(flowName string, repo *forjfile.RepoStruct, Forjfile *for
 	} else {
 		return fmt.Errorf("Internal Error! Unable to find '%s' flow in memory", flowName)
 	}
-	return flow.apply(repo, Forjfile)
+
+	var trace *FlowTrace
+	if fs.traces != nil {
+		trace = &FlowTrace{Flow: flowName, File: flow.file, Tasks: []*FlowTaskTrace{}}
+		if repo != nil {
+			trace.Repo, _ = repo.GetString("name")
+		}
+		fs.traces = append(fs.traces, trace)
+	}
+	err := flow.apply(repo, Forjfile, trace)
+	if trace != nil {
+		trace.sortChanges()
+		if err != nil {
+			trace.Error = err.Error()
+		}
+	}
+	return err
+}
+
+// StartTrace starts recording what next flows applied do. See Traces.
+func (fs *Flows) StartTrace() {
+	if fs == nil {
+		return
+	}
+	fs.traces = []*FlowTrace{}
+}
+
+// Traces returns what each flow applied since StartTrace did.
+func (fs *Flows) Traces() []*FlowTrace {
+	if fs == nil {
+		return nil
+	}
+	return fs.traces
 }
@@ -13,4 +13,7 @@ type FlowTaskDef struct {
 	List FlowTaskLists `yaml:"loop-on-list"`
 
 	Set FlowTaskSet // key1: object, key2: instance, key3: value key, then value
+
+	file  string         // Flow file which defines the task. See Flows.composeFlow
+	lines map[string]int // Line of each value set, by object/instance/key. See setTasksFile
 }
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alecthomas/kingpin"
+	"github.com/forj-oss/forjj-modules/cli/interface"
+)
+
+// flowCmd is the `forjj flow` command, to help writing flows.
+type flowCmd struct {
+	flow    *kingpin.CmdClause
+	context secretsContext
+
+	explain struct {
+		cmd  *kingpin.CmdClause
+		repo *string
+	}
+}
+
+func (f *flowCmd) init(app *kingpin.Application) {
+	if f == nil || app == nil {
+		return
+	}
+
+	f.flow = app.Command("flow", flow_cmd_help)
+	f.context.init()
+
+	// Following flags are parseable by cli, and used by ParseContext (so required).
+	f.context.flag(deployToArg,
+		f.flow.Flag("deploy-env", flowDeployEnvHelp).Envar("FORJJ_DEPLOY_ENV")).String()
+	f.context.flag(infra_path_f,
+		f.flow.Flag(infra_path_f, infra_path_help)).Envar("FORJJ_INFRA").Short('W').String()
+	f.context.flag("contribs-repo",
+		f.flow.Flag("contribs-repo", contribs_repo_help).Envar("CONTRIBS_REPO").Default(defaultContribsRepo)).String()
+	f.context.flag("flows-repo",
+		f.flow.Flag("flows-repo", flows_repo_help).Envar("FLOWS_REPO").Default(defaultFlowRepo)).String()
+	f.context.flag("repotemplates-repo",
+		f.flow.Flag("repotemplates-repo", repotemplates_repo_help).Envar("REPOTEMPLATES_REPO").Default(defaultRepoTemplate)).String()
+
+	f.explain.cmd = f.flow.Command("explain", flowExplainHelp)
+	f.explain.repo = f.explain.cmd.Arg("repo", flowExplainRepoHelp).String()
+}
+
+func (f *flowCmd) action(action string) (err error) {
+	actions := strings.Split(action, " ")
+	switch actions[1] {
+	case "explain":
+		err = forj_app.FlowExplain(*f.explain.repo)
+	}
+	if err != nil {
+		return fmt.Errorf("Forjj flow %s issue. %s", actions[1], err)
+	}
+	return
+}
+
+// DefineContext define cli Context to permit ParseContext to retrieve
+// common variable set.
+func (f *flowCmd) DefineContext(context clier.ParseContexter) {
+	f.context.defineContext(context)
+}
+
+// GetStringValue Return a field value from the given context (parse time, or after)
+func (f *flowCmd) GetStringValue(field string) (value string, found, isDefault bool, _ error) {
+	return f.context.GetStringValue(field)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"forjj/flow"
+	"strings"
+)
+
+// FlowExplain applies flows on the in memory Forjfile as `forjj update` does, and displays what each
+// flow task did: `if` rules evaluated with their rendered values, `loop-on-list` items and values set or
+// deleted.
+//
+// If repo is set, only flows applied on the Forjfile and on this repository are displayed.
+func (a *Forj) FlowExplain(repo string) error {
+	ffd, err := a.prepareFlows(false)
+	if err != nil {
+		return err
+	}
+	if repo != "" {
+		if _, found := ffd.Repos[repo]; !found {
+			return fmt.Errorf("Repository '%s' is not defined in deployment '%s'", repo, a.f.GetDeployment())
+		}
+	}
+
+	a.flows.StartTrace()
+	applyErr := a.FlowApply()
+
+	traces := make([]*flow.FlowTrace, 0, len(a.flows.Traces()))
+	for _, trace := range a.flows.Traces() {
+		if repo == "" || trace.Repo == "" || trace.Repo == repo {
+			traces = append(traces, trace)
+		}
+	}
+
+	if a.output.isJSON() {
+		a.output.setData(traces)
+		return applyErr
+	}
+
+	for _, trace := range traces {
+		onWhat := "Forjfile"
+		if trace.Repo != "" {
+			onWhat = "repository '" + trace.Repo + "'"
+		}
+		a.output.printf("Flow '%s' on %s%s:\n", trace.Flow, onWhat, explainLocation(trace.File, 0))
+		if len(trace.Tasks) == 0 {
+			a.output.println("  No tasks.")
+		}
+		for _, task := range trace.Tasks {
+			a.printFlowTask(task, trace.File)
+		}
+		if trace.Error != "" {
+			a.output.printf("  error: %s\n", trace.Error)
+		}
+		a.output.println()
+	}
+	return applyErr
+}
+
+// printFlowTask prints a task trace. The task file is shown if the task comes from another flow than flowFile.
+func (a *Forj) printFlowTask(task *flow.FlowTaskTrace, flowFile string) {
+	status := "skipped"
+	if task.Applied {
+		status = "applied"
+	}
+	from := ""
+	if task.File != flowFile {
+		from = explainLocation(task.File, 0)
+	}
+	a.output.printf("- task '%s' (%s)%s: %s\n", task.Name, task.Description, from, status)
+	for _, rule := range task.If {
+		a.output.printf("    if '%s' => '%s': %t\n", rule.Rule, rule.Rendered, rule.Result)
+		if rule.Error != "" {
+			a.output.printf("      error: %s\n", rule.Error)
+		}
+	}
+	for _, list := range task.Lists {
+		parameters := ""
+		if len(list.Parameters) > 0 {
+			parameters = "(" + strings.Join(list.Parameters, ", ") + ")"
+		}
+		a.output.printf("    loop on %s = %s%s: [%s]\n", list.Name, list.List, parameters, strings.Join(list.Items, ", "))
+	}
+	for _, iteration := range task.Iterations {
+		indent := "    "
+		if len(iteration.Items) > 0 {
+			items := make([]string, 0, len(iteration.Items))
+			for _, list := range task.Lists {
+				items = append(items, list.Name+"="+iteration.Items[list.Name])
+			}
+			a.output.printf("    with %s:\n", strings.Join(items, ", "))
+			indent += "  "
+		}
+		for _, change := range iteration.Changes {
+			switch {
+			case change.Key == "":
+				a.output.printf("%s+ %s/%s\n", indent, change.Object, change.Instance)
+			case change.Deleted:
+				a.output.printf("%s- %s/%s/%s\n", indent, change.Object, change.Instance, change.Key)
+			default:
+				a.output.printf("%s= %s/%s/%s: '%s'\n", indent, change.Object, change.Instance, change.Key, change.Value)
+			}
+		}
+		if iteration.Error != "" {
+			a.output.printf("%serror: %s\n", indent, iteration.Error)
+		}
+	}
+	if task.Error != "" {
+		a.output.printf("    error: %s\n", task.Error)
+	}
+}
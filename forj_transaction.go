@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"forjj/git"
+	"log"
+	"strings"
+	"sync"
+)
+
+// forjTransaction keeps infra and deploy repositories starting points while drivers update them.
+type forjTransaction struct {
+	repos  []*git.Transaction
+	lock   sync.Mutex
+	ended  bool
+	states []pendingPluginState // Plugin states to save when the transaction is committed.
+}
+
+// pendingPluginState is a plugin state applied during the transaction.
+type pendingPluginState struct {
+	file  string
+	state pluginState
+}
+
+// beginTransaction records the infra and the current deploy repositories starting points.
+func (a *Forj) beginTransaction() (t *forjTransaction, _ error) {
+	t = new(forjTransaction)
+
+	repos := [][]string{{"infra", a.f.InfraPath()}}
+	if a.d != nil && a.d.GetRepoPath() != "" {
+		repos = append(repos, []string{"deploy '" + a.d.Name() + "'", a.d.GetRepoPath()})
+	}
+
+	for _, repo := range repos {
+		transaction, err := git.Begin(repo[0], repo[1])
+		if err != nil {
+			return nil, err
+		}
+		t.repos = append(t.repos, transaction)
+	}
+	a.transaction = t
+	return
+}
+
+// keepPluginState delays the save of a plugin state until the transaction is committed.
+// It returns false if there is no transaction running.
+func (t *forjTransaction) keepPluginState(file string, state pluginState) bool {
+	if t == nil {
+		return false
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.ended {
+		return false
+	}
+	t.states = append(t.states, pendingPluginState{file: file, state: state})
+	return true
+}
+
+// commit ends the transaction, once the work done is committed. Plugin states applied are saved.
+func (t *forjTransaction) commit() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.ended = true
+	for _, pending := range t.states {
+		if err := pending.state.save(pending.file); err != nil {
+			log.Printf("Warning. Unable to save the plugin state. %s", err)
+		}
+	}
+	t.states = nil
+}
+
+// rollback restores all repositories of the transaction, and returns the cause error completed
+// with what was rolled back.
+//
+// Plugin states applied are not saved.
+func (t *forjTransaction) rollback(cause error) error {
+	t.lock.Lock()
+	t.ended = true
+	t.states = nil
+	t.lock.Unlock()
+
+	log.Print("-------------------------------------------")
+	log.Printf("Rolling back infra and deploy repositories due to: %s", cause)
+
+	done := make([]string, 0, len(t.repos))
+	for _, repo := range t.repos {
+		report, err := repo.Rollback()
+		for _, line := range report {
+			log.Printf("Rollback: %s", line)
+		}
+		done = append(done, report...)
+		if err != nil {
+			log.Printf("Rollback: %s", err)
+			done = append(done, err.Error())
+		}
+	}
+	log.Print("-------------------------------------------")
+	return fmt.Errorf("%s\nRollback:\n- %s", cause, strings.Join(done, "\n- "))
+}
@@ -4,20 +4,35 @@ import (
 	"forjj/forjfile"
 	"fmt"
 	"forjj/utils"
-)
+	"strings"
 
-// TODO: Be able to choose where to load one of more (merged) Forjfiles.
-// TODO: Be able to load from url.
+	"github.com/forj-oss/forjj-modules/trace"
+)
 
+// LoadForjfile loads the Forjfile models given with --forjfile-path.
+// Several models (path or URL), comma separated, are merged in order.
 func (a *Forj)LoadForjfile(action string) error {
+	models := []string{}
 	if v, found, _, err := a.cli.GetStringValue("_app","forjj", forjfile_path_f) ; err != nil {
 		return fmt.Errorf("Unable to find '%s' flag from '%s' action. %s", forjfile_path_f, action, err)
 	} else {
 		if found {
-			a.forjfile_tmpl_path, _ = utils.Abs(v)
+			for _, model := range strings.Split(v, ",") {
+				if model = strings.TrimSpace(model); model == "" {
+					continue
+				}
+				if !strings.HasPrefix(model, "http://") && !strings.HasPrefix(model, "https://") {
+					model, _ = utils.Abs(model)
+					a.forjfile_tmpl_paths = append(a.forjfile_tmpl_paths, model)
+				}
+				models = append(models, model)
+			}
 		}
 	}
-	f, found, err := forjfile.LoadTmpl(a.forjfile_tmpl_path)
+	if len(models) == 0 {
+		models = append(models, "")
+	}
+	f, found, err := forjfile.LoadTmpls(models)
 	if err != nil {
 		return err
 	}
@@ -25,10 +40,18 @@ func (a *Forj)LoadForjfile(action string) error {
 		return nil
 	}
 
+	if layers := f.Layers(); len(layers) > 1 {
+		msg := fmt.Sprintf("Forjfile models merged: %s\n", strings.Join(layers, ", "))
+		for _, object := range f.Objects() {
+			msg += fmt.Sprintf("- %s '%s' from %s\n", object.Object, object.Name, strings.Join(object.Layers, ", "))
+		}
+		gotrace.Info(msg)
+		a.output.setData(f.Objects())
+	}
+
 	// Load Forjfile to Forj internal.
 	a.w.SetFrom(f.Workspace)
 	a.f.SetFromTemplate(f)
 
 	return nil
 }
-
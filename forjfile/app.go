@@ -3,6 +3,7 @@ package forjfile
 import (
 	"fmt"
 	"forjj/sources_info"
+	"strings"
 
 	"github.com/forj-oss/goforjj"
 )
@@ -11,6 +12,8 @@ const (
 	appName   = "name"
 	appType   = "type"
 	appDriver = "driver"
+	// AppDependsOn is the list of application instances to execute before this one.
+	AppDependsOn = "depends-on"
 )
 
 type AppStruct struct {
@@ -24,6 +27,8 @@ type AppYamlStruct struct {
 	Type    string
 	Driver  string
 	Version string
+	// List of applications instances which must be executed before this one.
+	DependsOn []string `yaml:"depends-on,omitempty"`
 	// TODO: Support for object dedicated to the application instance (not shared)
 	// Objects map[string]map[string]string
 	Flows map[string]AppFlowYaml `yaml:",omitempty"`
@@ -121,6 +126,8 @@ func (a *AppStruct) Get(flag string) (value *goforjj.ValueStruct, found bool, so
 		value, found = value.Set(a.Type), (a.Type != "")
 	case appDriver:
 		value, found = value.Set(a.Driver), (a.Driver != "")
+	case AppDependsOn:
+		value, found = value.SetIfFound(a.DependsOn, len(a.DependsOn) > 0)
 	default:
 		v, f := a.more[flag]
 		value, found = value.SetIfFound(v.Get(), f)
@@ -171,6 +178,18 @@ func (a *AppStruct) set(source, flag, value string, set func(*ForjValue, string)
 			a.forge.dirty()
 			updated = true
 		}
+	case AppDependsOn:
+		dependsOn := make([]string, 0, strings.Count(value, ",")+1)
+		for _, instance := range strings.Split(value, ",") {
+			if instance = strings.TrimSpace(instance); instance != "" {
+				dependsOn = append(dependsOn, instance)
+			}
+		}
+		if strings.Join(a.DependsOn, ",") != strings.Join(dependsOn, ",") {
+			a.DependsOn = dependsOn
+			a.forge.dirty()
+			updated = true
+		}
 	default:
 		if a.more == nil {
 			a.more = make(ForjValues)
@@ -191,6 +210,14 @@ func (a *AppStruct) set(source, flag, value string, set func(*ForjValue, string)
 	return
 }
 
+// GetDependsOn returns the list of application instances to execute before this one.
+func (a *AppStruct) GetDependsOn() []string {
+	if a == nil {
+		return nil
+	}
+	return a.DependsOn
+}
+
 func (a *AppStruct) set_forge(f *ForgeYaml) {
 	if a == nil {
 		return
@@ -204,7 +231,12 @@ func (a *AppStruct) mergeFrom(from *AppStruct) {
 	}
 	for _, flag := range from.Flags() {
 		if v, found, source := from.Get(flag); found {
+			a.sources = a.sources.Merge(flag, from.sources)
 			a.set(source, flag, v.GetString(), (*ForjValue).Set)
 		}
 	}
+	if v, found, source := from.Get(AppDependsOn); found {
+		a.sources = a.sources.Merge(AppDependsOn, from.sources)
+		a.set(source, AppDependsOn, strings.Join(v.GetStringSlice(), ","), (*ForjValue).Set)
+	}
 }
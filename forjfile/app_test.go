@@ -225,3 +225,38 @@ func TestApp(t *testing.T) {
 
 }
 
+
+func TestAppDependsOn(t *testing.T) {
+	assert := assert.New(t)
+
+	/*********************************/
+	testCase := "when depends-on is set from a comma separated list"
+
+	app := NewAppStruct()
+	updated := app.Set("source", AppDependsOn, "github, gitlab,,")
+
+	assert.Truef(updated, "Expect Set to return true %s", testCase)
+	assert.Equalf([]string{"github", "gitlab"}, app.GetDependsOn(), "Expect depends-on list to be set %s", testCase)
+
+	value, found, source := app.Get(AppDependsOn)
+	assert.Truef(found, "Expect depends-on is found %s", testCase)
+	assert.Equalf("source", source, "Expect source to be set properly %s", testCase)
+	assert.Equalf([]string{"github", "gitlab"}, value.GetStringSlice(), "Expect depends-on to be properly set %s", testCase)
+
+	assert.Falsef(app.Set("source", AppDependsOn, "github,gitlab"), "Expect Set to return false when list is identical %s", testCase)
+
+	/*********************************/
+	testCase = "when app is merged"
+
+	app2 := NewAppStruct()
+	app2.mergeFrom(app)
+
+	assert.Equalf([]string{"github", "gitlab"}, app2.GetDependsOn(), "Expect depends-on to be merged %s", testCase)
+
+	/*********************************/
+	testCase = "when depends-on is removed"
+
+	assert.Truef(app.Set("source", AppDependsOn, ""), "Expect Set to return true %s", testCase)
+	_, found, _ = app.Get(AppDependsOn)
+	assert.Falsef(found, "Expect depends-on is not found %s", testCase)
+}
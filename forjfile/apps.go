@@ -24,7 +24,7 @@ func (a AppsStruct) mergeFrom(from AppsStruct) AppsStruct {
 			app.mergeFrom(appFrom)
 		} else {
 			
-			a[k] = appFrom
+			a[k] = appFrom.copy()
 		}
 	}
 	return a
@@ -0,0 +1,92 @@
+package forjfile
+
+// Copies of Forjfile objects. Merging a Forjfile into another one (See DeployForgeYaml.mergeFrom) updates the
+// objects of the Forjfile merged in place. So objects added from another Forjfile are copied, to never update
+// the master or a deployment Forjfile when building a merged one.
+
+// copy returns a copy of the repository. Applications connected are shared.
+func (r *RepoStruct) copy() (ret *RepoStruct) {
+	if r == nil {
+		return
+	}
+	ret = new(RepoStruct)
+	*ret = *r
+	ret.More = copyStringMap(r.More)
+	ret.Apps = copyStringMap(r.Apps)
+	if r.apps != nil {
+		ret.apps = make(map[string]*AppStruct)
+		for name, app := range r.apps {
+			ret.apps[name] = app
+		}
+	}
+	if r.Flow.objects != nil {
+		ret.Flow.objects = make(map[string]map[string]string)
+		for name, object := range r.Flow.objects {
+			ret.Flow.objects[name] = copyStringMap(object)
+		}
+	}
+	ret.sources = r.sources.Copy()
+	return
+}
+
+// copy returns a copy of the application.
+func (a *AppStruct) copy() (ret *AppStruct) {
+	if a == nil {
+		return
+	}
+	ret = new(AppStruct)
+	*ret = *a
+	ret.DependsOn = append([]string(nil), a.DependsOn...)
+	if a.Flows != nil {
+		ret.Flows = make(map[string]AppFlowYaml)
+		for name, flow := range a.Flows {
+			flow.Options = copyStringMap(flow.Options)
+			ret.Flows[name] = flow
+		}
+	}
+	if a.more != nil {
+		ret.more = make(ForjValues)
+		for key, value := range a.more {
+			ret.more[key] = value
+		}
+	}
+	ret.More = copyStringMap(a.More)
+	ret.sources = a.sources.Copy()
+	return
+}
+
+// copy returns a copy of the user.
+func (u *UserStruct) copy() (ret *UserStruct) {
+	if u == nil {
+		return
+	}
+	ret = new(UserStruct)
+	*ret = *u
+	ret.More = copyStringMap(u.More)
+	ret.sources = u.sources.Copy()
+	return
+}
+
+// copy returns a copy of the group.
+func (g *GroupStruct) copy() (ret *GroupStruct) {
+	if g == nil {
+		return
+	}
+	ret = new(GroupStruct)
+	*ret = *g
+	ret.Members = append([]string(nil), g.Members...)
+	ret.More = copyStringMap(g.More)
+	ret.sources = g.sources.Copy()
+	return
+}
+
+func copyStringMap(from map[string]string) (ret map[string]string) {
+	if from == nil {
+		return
+	}
+	ret = make(map[string]string)
+	for key, value := range from {
+		ret[key] = value
+	}
+	return
+}
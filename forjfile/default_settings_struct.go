@@ -15,6 +15,18 @@ type DefaultSettingsStruct struct {
 	sources          *sourcesinfo.Sources
 }
 
+// Flags provide the list of existing keys in default settings.
+func (s *DefaultSettingsStruct) Flags() (flags []string) {
+	flags = make([]string, 3, 3+len(s.More))
+	flags[0] = "flow"
+	flags[1] = "dev-deploy"
+	flags[2] = "upstream-instance" // TODO: Remove obsolete reference to "upstream-instance"
+	for k := range s.More {
+		flags = append(flags, k)
+	}
+	return
+}
+
 // Get return the value of the default setting.
 func (s *DefaultSettingsStruct) Get(key string) (value *goforjj.ValueStruct, found bool, source string) {
 	source = s.sources.Get(key)
@@ -46,13 +58,11 @@ func (s *DefaultSettingsStruct) Set(source, key, value string) {
 			s.Flow = value
 			s.forge.dirty()
 		}
-		return
 	case "dev-deploy":
 		if s.DevDeploy != value {
 			s.DevDeploy = value
 			s.forge.dirty()
 		}
-		return
 	default:
 		if v, found := s.More[key]; found && value == "" {
 			delete(s.More, key)
@@ -0,0 +1,30 @@
+package forjfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultSettingsFlags(t *testing.T) {
+	assert := assert.New(t)
+
+	s := new(DefaultSettingsStruct)
+	s.More = make(map[string]string)
+	s.Set("test", "upstream-instance", "github")
+	s.Set("test", "flow", "default")
+
+	/*************************************/
+	testCase := "when default settings are set"
+
+	flags := s.Flags()
+	for _, flag := range flags {
+		_, found, _ := s.Get(flag)
+		if flag == "dev-deploy" {
+			assert.Falsef(found, "Expect '%s' to not be set %s", flag, testCase)
+			continue
+		}
+		assert.Truef(found, "Expect '%s' to be found %s", flag, testCase)
+	}
+	assert.Containsf(flags, "upstream-instance", "Expect every key supported by Get to be listed %s", testCase)
+}
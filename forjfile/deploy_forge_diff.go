@@ -0,0 +1,110 @@
+package forjfile
+
+import (
+	"sort"
+)
+
+// ForjfileDiff is a value defined in a deployment Forjfile which is different or missing in another one.
+type ForjfileDiff struct {
+	Object   string `json:"object"`
+	Instance string `json:"instance"`
+	Key      string `json:"key"`
+	From     string `json:"from"`
+	To       string `json:"to,omitempty"`
+	New      bool   `json:"new,omitempty"` // true if the key is not defined in the target Forjfile.
+}
+
+// Path returns the object/instance/key path of the value, or object/key for settings without instance.
+func (d ForjfileDiff) Path() string {
+	if d.Instance == "" {
+		return d.Object + "/" + d.Key
+	}
+	return d.Object + "/" + d.Instance + "/" + d.Key
+}
+
+// diffObjects are the Forjfile objects compared by Diff, before plugin objects.
+var diffObjects = []string{"settings", "user", "group", "app", "repo"}
+
+// diffIgnoredKeys are keys calculated by forjj. They are never compared.
+var diffIgnoredKeys = map[string][]string{
+	"settings": {settingsDefault, settingsDeployTo, "dev-deploy"},
+	"app":      {"name"},
+	"group":    {groupMembers},
+	"repo": {
+		FieldRepoName,
+		FieldRepoUpstream,
+		FieldRepoGitRemote,
+		FieldRepoRemote,
+		FieldRepoRemoteURL,
+		FieldRepoDeployName,
+		FieldRepoRole,
+		FieldCurrentDeployRepo,
+		"deployment-type",
+	},
+}
+
+// Diff returns values defined in f which are different or missing in `to`.
+//
+// Values only defined in `to` are not reported. The result is sorted by object, instance and key.
+func (f *DeployForgeYaml) Diff(to *DeployForgeYaml) (ret []ForjfileDiff) {
+	ret = []ForjfileDiff{}
+	if f == nil || !f.init() {
+		return
+	}
+
+	objects := make([]string, 0, len(diffObjects)+len(f.More))
+	objects = append(objects, diffObjects...)
+	more := make([]string, 0, len(f.More))
+	for object := range f.More {
+		more = append(more, object)
+	}
+	sort.Strings(more)
+	objects = append(objects, more...)
+
+	for _, object := range objects {
+		instances := f.GetInstances(object)
+		if object == "settings" {
+			instances = []string{"", settingsDefault}
+		}
+		sort.Strings(instances)
+		for _, instance := range instances {
+			keys := f.GetKeys(object, instance)
+			sort.Strings(keys)
+			for _, key := range keys {
+				if isDiffIgnored(object, key) {
+					continue
+				}
+				v, found, _ := f.GetString(object, instance, key)
+				if !found || v == "" {
+					continue
+				}
+				var toValue string
+				toFound := false
+				if to != nil {
+					toValue, toFound, _ = to.GetString(object, instance, key)
+				}
+				if toFound && toValue == v {
+					continue
+				}
+				ret = append(ret, ForjfileDiff{
+					Object:   object,
+					Instance: instance,
+					Key:      key,
+					From:     v,
+					To:       toValue,
+					New:      !toFound || toValue == "",
+				})
+			}
+		}
+	}
+	return
+}
+
+func isDiffIgnored(object, key string) bool {
+	for _, ignored := range diffIgnoredKeys[object] {
+		if ignored == key {
+			return true
+		}
+	}
+	return false
+}
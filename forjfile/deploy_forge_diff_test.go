@@ -0,0 +1,57 @@
+package forjfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeployForgeYamlDiff(t *testing.T) {
+	assert := assert.New(t)
+
+	forge := NewForgeYaml()
+	from := NewDeployForgeYaml()
+	from.Init(forge)
+	to := NewDeployForgeYaml()
+	to.Init(forge)
+
+	from.Set("test", "repo", "myrepo", FieldRepoTitle, "New title")
+	from.Set("test", "repo", "myrepo", FieldRepoFlow, "github-pr")
+	from.Set("test", "app", "jenkins", "type", "ci")
+	from.Set("test", "user", "alice", "role", "admin")
+	from.Set("test", "settings", "default", "flow", "github-pr")
+	to.Set("test", "repo", "myrepo", FieldRepoTitle, "Old title")
+	to.Set("test", "repo", "myrepo", FieldRepoFlow, "github-pr")
+	to.Set("test", "repo", "other", FieldRepoTitle, "Only in target")
+
+	/*************************************/
+	testCase := "when comparing two deployment Forjfiles"
+
+	changes := from.Diff(to)
+	paths := make(map[string]ForjfileDiff)
+	for _, change := range changes {
+		paths[change.Path()] = change
+	}
+	assert.Lenf(changes, 4, "Expect 4 changes %s", testCase)
+	if v, found := paths["repo/myrepo/title"]; assert.Truef(found, "Expect repo title change %s", testCase) {
+		assert.Equalf("New title", v.From, "Expect the source value %s", testCase)
+		assert.Equalf("Old title", v.To, "Expect the target value %s", testCase)
+		assert.Falsef(v.New, "Expect an updated value %s", testCase)
+	}
+	if v, found := paths["app/jenkins/type"]; assert.Truef(found, "Expect new app %s", testCase) {
+		assert.Truef(v.New, "Expect a new value %s", testCase)
+	}
+	assert.Containsf(paths, "user/alice/role", "Expect new user %s", testCase)
+	assert.Containsf(paths, "settings/default/flow", "Expect default flow %s", testCase)
+	assert.NotContainsf(paths, "repo/myrepo/flow", "Expect identical values to be ignored %s", testCase)
+	assert.NotContainsf(paths, "repo/myrepo/name", "Expect calculated keys to be ignored %s", testCase)
+
+	/*************************************/
+	testCase = "when changes are applied to the target"
+
+	for _, change := range changes {
+		to.Set("promote", change.Object, change.Instance, change.Key, change.From)
+	}
+	assert.Emptyf(from.Diff(to), "Expect no more changes %s", testCase)
+	assert.Equalf("Only in target", to.Repos["other"].Title, "Expect target only values to be kept %s", testCase)
+}
@@ -0,0 +1,77 @@
+package forjfile
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FilterInstances returns instances of an object, in name order, which respect all rules.
+//
+// A rule is '<key>:<value>'. See matchRules
+func (f *DeployForgeYaml) FilterInstances(object string, rules ...string) (ret []string, err error) {
+	ret = []string{}
+	instances := f.GetInstances(object)
+	sort.Strings(instances)
+	for _, instance := range instances {
+		found, err := matchRules(func(key string) string {
+			v, _, _ := f.GetString(object, instance, key)
+			return v
+		}, rules...)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			ret = append(ret, instance)
+		}
+	}
+	return
+}
+
+// FilterDeployments returns deployments, in name order, which respect all rules.
+//
+// Rules keys are name, type, description, inherits or a deployment parameter. See FilterInstances.
+func (f *DeployForgeYaml) FilterDeployments(rules ...string) (ret []*DeploymentStruct, err error) {
+	ret = []*DeploymentStruct{}
+	if f == nil || f.forge == nil {
+		return
+	}
+	names := make([]string, 0, len(f.forge.Deployments))
+	for name := range f.forge.Deployments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		model := f.forge.Deployments[name].Model()
+		found, err := matchRules(model.Get, rules...)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			ret = append(ret, f.forge.Deployments[name])
+		}
+	}
+	return
+}
+
+// ObjectModel returns the model of an object instance.
+func (f *DeployForgeYaml) ObjectModel(object, instance string) ObjectModel {
+	return ObjectModel{forge: f, object: object, Name: instance}
+}
+
+// matchRules returns true if all '<key>:<value>' rules are respected.
+//
+// A rule is respected if the key value is the rule value. A key which is not defined has an empty value. So it
+// respects only '<key>:'.
+func matchRules(get func(key string) string, rules ...string) (_ bool, err error) {
+	for _, rule := range rules {
+		ruleToCheck := strings.SplitN(rule, ":", 2)
+		if len(ruleToCheck) != 2 {
+			return false, fmt.Errorf("rule '%s' is invalid. Format supported is '<key>:<value>'", rule)
+		}
+		if get(ruleToCheck[0]) != ruleToCheck[1] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,78 @@
+package forjfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeployForgeYamlFilters(t *testing.T) {
+	assert := assert.New(t)
+
+	forge := NewForgeYaml()
+	forge.ForjCore.Init(forge)
+	forge.set_defaults()
+	newDeploy := func(name, deployType string) {
+		deploy := new(DeploymentStruct)
+		deploy.name = name
+		deploy.Type = deployType
+		deploy.Pars = map[string]string{"region": "eu"}
+		forge.Deployments[name] = deploy
+	}
+	newDeploy("staging", DevDeployType)
+	newDeploy("production", ProDeployType)
+	core := &forge.ForjCore
+	core.Set("test", "user", "bob", userRole, "viewer")
+	core.Set("test", "user", "alice", userRole, "admin")
+	core.Set("test", "group", "ops", "role", "admin")
+	core.Groups["ops"].Members = []string{"alice"}
+
+	/*************************************/
+	testCase := "when filtering users"
+
+	users, err := core.FilterInstances("user")
+	assert.NoErrorf(err, "Expect no error %s", testCase)
+	assert.Equalf([]string{"alice", "bob"}, users, "Expect users in name order %s", testCase)
+	users, err = core.FilterInstances("user", userRole+":admin")
+	assert.NoErrorf(err, "Expect no error %s", testCase)
+	assert.Equalf([]string{"alice"}, users, "Expect admin users only %s", testCase)
+	users, err = core.FilterInstances("user", "undefined:value")
+	assert.NoErrorf(err, "Expect no error %s", testCase)
+	assert.Emptyf(users, "Expect undefined keys to not respect the rule %s", testCase)
+	users, err = core.FilterInstances("user", "undefined:")
+	assert.NoErrorf(err, "Expect no error %s", testCase)
+	assert.Lenf(users, 2, "Expect undefined keys to be empty %s", testCase)
+	_, err = core.FilterInstances("user", "admin")
+	assert.Errorf(err, "Expect an invalid rule error %s", testCase)
+
+	/*************************************/
+	testCase = "when filtering deployments"
+
+	deploys, err := core.FilterDeployments("type:" + ProDeployType)
+	assert.NoErrorf(err, "Expect no error %s", testCase)
+	if assert.Lenf(deploys, 1, "Expect 1 deployment %s", testCase) {
+		assert.Equalf("production", deploys[0].Model().Get("name"), "Expect the PRO deployment %s", testCase)
+		assert.Equalf("eu", deploys[0].Model().Get("region"), "Expect deployment parameters %s", testCase)
+	}
+	deploys, err = core.FilterDeployments("region:eu")
+	assert.NoErrorf(err, "Expect no error %s", testCase)
+	if assert.Lenf(deploys, 2, "Expect 2 deployments %s", testCase) {
+		assert.Equalf("production", deploys[0].Name(), "Expect deployments in name order %s", testCase)
+	}
+	forge.Deployments["staging"].Pars = nil
+	deploys, err = core.FilterDeployments("region:eu")
+	assert.NoErrorf(err, "Expect no error %s", testCase)
+	if assert.Lenf(deploys, 1, "Expect deployments without the key to be filtered out %s", testCase) {
+		assert.Equalf("production", deploys[0].Name(), "Expect the deployment with the key %s", testCase)
+	}
+
+	/*************************************/
+	testCase = "when getting an object model"
+
+	alice := core.ObjectModel("user", "alice")
+	assert.Equalf("alice", alice.Get("name"), "Expect the instance name %s", testCase)
+	assert.Equalf("admin", alice.Get(userRole), "Expect the user role %s", testCase)
+	member := GroupMemberModel{Group: core.ObjectModel("group", "ops"), User: alice}
+	assert.Equalf("ops", member.Get("group"), "Expect the group name %s", testCase)
+	assert.Equalf("alice", member.Get("name"), "Expect the member name %s", testCase)
+}
@@ -22,8 +22,10 @@ type DeployForgeYaml struct {
 	Users         UsersStruct
 	Groups        GroupsStruct
 	// Collection of Object/Name/Keys=values
-	More    map[string]map[string]ForjValues `yaml:",inline,omitempty"`
-	sources *sourcesinfo.Sources
+	More     map[string]map[string]ForjValues `yaml:",inline,omitempty"`
+	Includes []string                         `yaml:"includes,omitempty"` // Files to include. See includes.go
+	sources  map[string]*sourcesinfo.Sources  // More objects sources, by object/instance
+	included forjfileIncludes                 // Files included and objects they define.
 }
 
 // NewDeployForgeYaml creates an empty pre-initialized object.
@@ -238,6 +240,7 @@ func (f *DeployForgeYaml) SetHandler(source, object, name string, from func(stri
 			newuser := UserStruct{}
 			newuser.set_forge(f.forge)
 			f.Users[name] = &newuser
+			newuser.SetHandler(source, from, keys...)
 		}
 	case "group":
 		if f.Groups == nil {
@@ -249,6 +252,7 @@ func (f *DeployForgeYaml) SetHandler(source, object, name string, from func(stri
 			newgroup := GroupStruct{}
 			newgroup.set_forge(f.forge)
 			f.Groups[name] = &newgroup
+			newgroup.SetHandler(source, from, keys...)
 		}
 	case "app":
 		if f.Apps == nil {
@@ -362,6 +366,130 @@ func (f *DeployForgeYaml) HasApps(rules ...string) (found bool, err error) {
 	return
 }
 
+// HasInstance returns true if the object instance exists.
+func (f *DeployForgeYaml) HasInstance(object, name string) (found bool) {
+	if !f.init() {
+		return
+	}
+	switch object {
+	case "user":
+		_, found = f.Users[name]
+	case "group":
+		_, found = f.Groups[name]
+	case "app":
+		_, found = f.Apps[name]
+	case "repo":
+		_, found = f.Repos[name]
+	default:
+		if instances, f1 := f.More[object]; f1 {
+			_, found = instances[name]
+		}
+	}
+	return
+}
+
+// GetKeys returns the list of keys an object instance can have.
+func (f *DeployForgeYaml) GetKeys(object, instance string) (keys []string) {
+	if !f.init() {
+		return
+	}
+	switch object {
+	case "infra":
+		return f.Infra.Flags()
+	case "user":
+		if user, found := f.Users[instance]; found {
+			return user.Flags()
+		}
+	case "group":
+		if group, found := f.Groups[instance]; found {
+			return group.Flags()
+		}
+	case "app":
+		if app, found := f.Apps[instance]; found {
+			return app.Flags()
+		}
+	case "repo":
+		if repo, found := f.Repos[instance]; found {
+			return repo.Flags()
+		}
+	case "settings":
+		if instance == settingsDefault {
+			return f.ForjSettings.Default.Flags()
+		}
+		return f.ForjSettings.Flags()
+	default:
+		for key := range f.getInstance(object, instance) {
+			keys = append(keys, key)
+		}
+	}
+	return
+}
+
+// RemoveInstance removes the object instance. It returns false if the instance was not found.
+func (f *DeployForgeYaml) RemoveInstance(object, name string) (found bool) {
+	if !f.HasInstance(object, name) {
+		return
+	}
+	switch object {
+	case "user":
+		delete(f.Users, name)
+	case "group":
+		delete(f.Groups, name)
+	case "app":
+		delete(f.Apps, name)
+	case "repo":
+		delete(f.Repos, name)
+	default:
+		delete(f.More[object], name)
+	}
+	f.forge.dirty()
+	return true
+}
+
+// RenameInstance renames a repository or an application.
+// When an application is renamed, repositories and applications referring to it are updated.
+func (f *DeployForgeYaml) RenameInstance(object, name, newName string) error {
+	if !f.HasInstance(object, name) {
+		return fmt.Errorf("%s '%s' not found", object, name)
+	}
+	if name == newName {
+		return nil
+	}
+	if f.HasInstance(object, newName) {
+		return fmt.Errorf("Unable to rename %s '%s'. '%s' already exists", object, name, newName)
+	}
+
+	switch object {
+	case "repo":
+		repo := f.Repos[name]
+		delete(f.Repos, name)
+		repo.name = newName
+		f.Repos[newName] = repo
+	case "app":
+		app := f.Apps[name]
+		delete(f.Apps, name)
+		app.name = newName
+		f.Apps[newName] = app
+
+		for _, repo := range f.Repos {
+			repo.renameApp(name, newName)
+		}
+		f.Infra.renameApp(name, newName)
+		for _, app := range f.Apps {
+			for index, dependsOn := range app.DependsOn {
+				if dependsOn == name {
+					app.DependsOn[index] = newName
+				}
+			}
+		}
+	default:
+		return fmt.Errorf("Unable to rename %s '%s'. Only repo and app can be renamed", object, name)
+	}
+	f.renameIncluded(object, name, newName)
+	f.forge.dirty()
+	return nil
+}
+
 // ----------------- Create objects functions
 
 // NewRepoStruct create a new Repo in the Forjfile.
@@ -383,9 +511,10 @@ func (f *DeployForgeYaml) NewRepoStruct(name string) (repo *RepoStruct) {
 
 func (f *DeployForgeYaml) get(object, instance, key string) (value *goforjj.ValueStruct, found bool, source string) {
 	if obj, f1 := f.More[object]; f1 {
-		if instance, f2 := obj[instance]; f2 {
-			v, f3 := instance[key]
+		if data, f2 := obj[instance]; f2 {
+			v, f3 := data[key]
 			value, found = value.SetIfFound(v.Get(), f3)
+			source = f.sources[object+"/"+instance].Get(key)
 		}
 	}
 	return
@@ -426,7 +555,9 @@ func (f *DeployForgeYaml) setHandler(source, object, instance string, from func(
 				instanceData[key] = v
 				f.forge.updated = true
 			}
-			f.sources.Set(source, key, v.Get())
+		}
+		if v := instanceData[key]; v.Get() == value { // The value is now given by this source.
+			f.setSource(object, instance, key, sourcesinfo.Source{Source: source, Value: value})
 		}
 	}
 }
@@ -477,7 +608,23 @@ func (f *DeployForgeYaml) mergeFrom(from *DeployForgeYaml) error {
 	}
 	// if infra requires to be defined, it MUST be in the main Forjfile. No Infra merge is made from deployment.
 	for k, v := range from.More {
-		f.More[k] = v
+		if f.More == nil {
+			f.More = make(map[string]map[string]ForjValues)
+		}
+		if f.More[k] == nil {
+			f.More[k] = make(map[string]ForjValues)
+		}
+		for instance, data := range v {
+			// Merged key by key. So a deployment can override a few keys only.
+			if f.More[k][instance] == nil {
+				f.More[k][instance] = make(ForjValues)
+			}
+			sources := from.sources[k+"/"+instance]
+			for key, value := range data {
+				f.More[k][instance][key] = value
+				f.mergeSource(k, instance, key, sources)
+			}
+		}
 	}
 	return nil
 }
@@ -550,6 +697,15 @@ func (f *DeployForgeYaml) GetRepo(name string) (r *RepoStruct, found bool) {
 	return
 }
 
+// GetDeployment returns the deployment merged in this Forjfile. Not found in the master Forjfile.
+func (f *DeployForgeYaml) GetDeployment() (deploy *DeploymentStruct, found bool) {
+	if f == nil || f.forge == nil || f.deployTo == "" {
+		return
+	}
+	deploy, found = f.forge.Deployments[f.deployTo]
+	return
+}
+
 func (f *DeployForgeYaml) Model() ForgeModel {
 	model := ForgeModel{
 		forge: f,
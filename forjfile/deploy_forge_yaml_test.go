@@ -0,0 +1,156 @@
+package forjfile
+
+import (
+	"forjj/sources_info"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func TestDeployForgeYamlInstances(t *testing.T) {
+	assert := assert.New(t)
+
+	forge := NewForgeYaml()
+	f := &forge.ForjCore
+	f.Init(forge)
+
+	f.Set("test", "app", "github", "type", "upstream")
+	f.Set("test", "app", "jenkins", "type", "ci")
+	f.Apps["jenkins"].DependsOn = []string{"github"}
+	f.Set("test", "repo", "myrepo", FieldRepoTitle, "My repo")
+	f.Repos["myrepo"].Apps = map[string]string{"upstream": "github"}
+	forge.updated = false
+
+	/*************************************/
+	testCase := "when checking instances"
+
+	assert.Truef(f.HasInstance("app", "github"), "Expect app github to exist %s", testCase)
+	assert.Truef(f.HasInstance("repo", "myrepo"), "Expect repo myrepo to exist %s", testCase)
+	assert.Falsef(f.HasInstance("repo", "unknown"), "Expect repo unknown to not exist %s", testCase)
+
+	/*************************************/
+	testCase = "when renaming an application"
+
+	assert.NoErrorf(f.RenameInstance("app", "github", "gh"), "Expect rename to succeed %s", testCase)
+	assert.Falsef(f.HasInstance("app", "github"), "Expect github to be removed %s", testCase)
+	if assert.Truef(f.HasInstance("app", "gh"), "Expect gh to exist %s", testCase) {
+		assert.Equalf("gh", f.Apps["gh"].Name(), "Expect app name to be updated %s", testCase)
+	}
+	assert.Equalf("gh", f.Repos["myrepo"].Apps["upstream"], "Expect repo relation to be updated %s", testCase)
+	assert.Equalf([]string{"gh"}, f.Apps["jenkins"].DependsOn, "Expect depends-on to be updated %s", testCase)
+	assert.Truef(forge.updated, "Expect Forjfile to be updated %s", testCase)
+
+	/*************************************/
+	testCase = "when renaming to an existing instance"
+
+	assert.Errorf(f.RenameInstance("app", "gh", "jenkins"), "Expect rename to fail %s", testCase)
+	assert.Errorf(f.RenameInstance("repo", "unknown", "other"), "Expect rename to fail %s", testCase)
+
+	/*************************************/
+	testCase = "when renaming a repository"
+
+	assert.NoErrorf(f.RenameInstance("repo", "myrepo", "newrepo"), "Expect rename to succeed %s", testCase)
+	if assert.Truef(f.HasInstance("repo", "newrepo"), "Expect newrepo to exist %s", testCase) {
+		assert.Equalf("newrepo", f.Repos["newrepo"].name, "Expect repo name to be updated %s", testCase)
+	}
+
+	/*************************************/
+	testCase = "when removing instances"
+
+	assert.Truef(f.RemoveInstance("repo", "newrepo"), "Expect repo to be removed %s", testCase)
+	assert.Falsef(f.RemoveInstance("repo", "newrepo"), "Expect nothing removed %s", testCase)
+	assert.Falsef(f.HasInstance("repo", "newrepo"), "Expect repo to not exist %s", testCase)
+}
+
+func TestDeployForgeYamlGetKeys(t *testing.T) {
+	assert := assert.New(t)
+
+	forge := NewForgeYaml()
+	f := &forge.ForjCore
+	f.Init(forge)
+
+	f.Set("test", "app", "github", "type", "upstream")
+	f.Set("test", "projects", "myproject", "remote-type", "github")
+
+	/*************************************/
+	testCase := "when getting keys of an application"
+
+	keys := f.GetKeys("app", "github")
+	assert.Containsf(keys, "type", "Expect type key %s", testCase)
+	assert.Containsf(keys, "driver", "Expect driver key %s", testCase)
+
+	/*************************************/
+	testCase = "when getting keys of a plugin object"
+
+	assert.Equalf([]string{"remote-type"}, f.GetKeys("projects", "myproject"), "Expect plugin object keys %s", testCase)
+
+	/*************************************/
+	testCase = "when getting keys of an unknown instance"
+
+	assert.Emptyf(f.GetKeys("repo", "unknown"), "Expect no keys %s", testCase)
+	assert.Containsf(f.GetKeys("settings", "default"), "flow", "Expect default settings keys %s", testCase)
+}
+
+func TestDeployForgeYamlSourcesHistory(t *testing.T) {
+	assert := assert.New(t)
+
+	forge := NewForgeYaml()
+	load := func(file, data string) *DeployForgeYaml {
+		forjfile := NewDeployForgeYaml()
+		assert.NoErrorf(yaml.Unmarshal([]byte(data), forjfile), "Expect %s to be loaded", file)
+		forjfile.initDefaults(forge)
+		forjfile.setFileSources(file, newYamlDocument([]byte(data)))
+		return forjfile
+	}
+	master := load("Forjfile", `repositories:
+  myrepo:
+    title: Master repo
+projects:
+  myproject:
+    remote-type: git
+`)
+	deploy := load("deployments/dev/Forjfile", `# DEV deployment
+repositories:
+  myrepo:
+    flow:
+      name: github-pr
+    title: DEV repo
+projects:
+  myproject:
+    remote-type: github
+`)
+
+	result := NewDeployForgeYaml()
+	result.Init(forge)
+	result.Repos["myrepo"] = &RepoStruct{}
+	result.Repos["myrepo"].set_forge(forge)
+
+	/*************************************/
+	testCase := "when merging master and deployment Forjfiles"
+
+	assert.NoErrorf(result.mergeFrom(master), "Expect master merge to succeed %s", testCase)
+	assert.NoErrorf(result.mergeFrom(deploy), "Expect deployment merge to succeed %s", testCase)
+
+	history := result.GetSources("repo", "myrepo").History(FieldRepoTitle)
+	assert.Containsf(history, sourcesinfo.Source{Source: SourceForjfile, Value: "Master repo", File: "Forjfile", Line: 3},
+		"Expect master value location recorded %s", testCase)
+	if assert.NotEmptyf(history, "Expect repo history %s", testCase) {
+		assert.Equalf(sourcesinfo.Source{Source: SourceForjfile, Value: "DEV repo", File: "deployments/dev/Forjfile", Line: 6},
+			history[len(history)-1], "Expect deployment value location recorded %s", testCase)
+	}
+	history = result.GetSources("repo", "myrepo").History(FieldRepoFlow)
+	if assert.NotEmptyf(history, "Expect repo flow history %s", testCase) {
+		assert.Equalf(4, history[len(history)-1].Line, "Expect the flow entry line recorded %s", testCase)
+	}
+
+	history = result.GetSources("projects", "myproject").History("remote-type")
+	if assert.NotEmptyf(history, "Expect plugin object history %s", testCase) {
+		last := history[len(history)-1]
+		assert.Equalf("github", last.Value, "Expect deployment value to win %s", testCase)
+		assert.Equalf(SourceForjfile, last.Source, "Expect Forjfile source %s", testCase)
+		assert.Equalf(9, last.Line, "Expect deployment line recorded %s", testCase)
+	}
+	_, _, source := result.Get("projects", "myproject", "remote-type")
+	assert.Equalf(SourceForjfile, source, "Expect source to be reported %s", testCase)
+}
@@ -17,6 +17,7 @@ type DeploymentCoreStruct struct {
 	repo             *RepoStruct // Source code Repository for the deployment.
 	Type             string
 	Pars             map[string]string `yaml:"parameters,omitempty"`
+	Inherits         string            `yaml:"inherits,omitempty"` // Deployment inherited by the deployment Forjfile.
 }
 
 // GetRepoPath returns the absolute path of the current deployment repository.
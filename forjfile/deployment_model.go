@@ -0,0 +1,29 @@
+package forjfile
+
+// DeploymentModel is the model of a deployment, for text/template.
+type DeploymentModel struct {
+	deploy *DeploymentStruct
+}
+
+// Model returns the deployment model.
+func (d *DeploymentStruct) Model() DeploymentModel {
+	return DeploymentModel{deploy: d}
+}
+
+// Get returns the deployment name, type, description, inherits or a deployment parameter.
+func (d DeploymentModel) Get(key string) string {
+	if d.deploy == nil {
+		return ""
+	}
+	switch key {
+	case "name":
+		return d.deploy.Name()
+	case "type":
+		return d.deploy.Type
+	case "description":
+		return d.deploy.Desc
+	case "inherits":
+		return d.deploy.Inherits
+	}
+	return d.deploy.Pars[key]
+}
@@ -0,0 +1,100 @@
+package forjfile
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DeploymentTypeStruct defines a deployment type and its rules, in forj-settings/deployment-types.
+type DeploymentTypeStruct struct {
+	Name          string
+	Single        bool     `yaml:",omitempty"`               // Only one deployment of this type can be declared.
+	DeployPublish bool     `yaml:"deploy-publish,omitempty"` // `forjj update` must be run with --deploy-publish.
+	Maintainers   []string `yaml:",omitempty"`               // Users or groups allowed to maintain. Anyone if empty.
+}
+
+// DeploymentTypes is the ordered list of deployment types. The order is the promotion pipeline.
+type DeploymentTypes []DeploymentTypeStruct
+
+// defaultDeploymentTypes are used when forj-settings/deployment-types is not declared.
+func defaultDeploymentTypes() DeploymentTypes {
+	return DeploymentTypes{
+		{Name: DevDeployType},
+		{Name: testDeployType},
+		{Name: ProDeployType, Single: true},
+	}
+}
+
+// Get returns the deployment type rules.
+func (t DeploymentTypes) Get(name string) (ret *DeploymentTypeStruct, found bool) {
+	for index := range t {
+		if t[index].Name == name {
+			return &t[index], true
+		}
+	}
+	return
+}
+
+// Names returns deployment types names, in promotion order.
+func (t DeploymentTypes) Names() (ret []string) {
+	ret = make([]string, len(t))
+	for index, deployType := range t {
+		ret[index] = deployType.Name
+	}
+	return
+}
+
+// Rank returns the position of the deployment type in the promotion pipeline.
+// Unknown types are ranked last.
+func (t DeploymentTypes) Rank(name string) int {
+	for index, deployType := range t {
+		if deployType.Name == name {
+			return index
+		}
+	}
+	return len(t)
+}
+
+// validate checks deployment types declaration.
+//
+// PRO is the deployment type which owns the infra repository. It must be declared, as a single deployment.
+func (t DeploymentTypes) validate() error {
+	for index, deployType := range t {
+		if deployType.Name == "" {
+			return fmt.Errorf("Deployment type declaration error in 'forj-settings/deployment-types'. Entry %d has no name", index+1)
+		}
+		if deployType.Name != strings.ToUpper(deployType.Name) {
+			return fmt.Errorf("Deployment type declaration error in 'forj-settings/deployment-types'. '%s' must be uppercase", deployType.Name)
+		}
+		if t.Rank(deployType.Name) != index {
+			return fmt.Errorf("Deployment type declaration error in 'forj-settings/deployment-types'. '%s' is declared twice", deployType.Name)
+		}
+	}
+	if pro, found := t.Get(ProDeployType); !found {
+		return fmt.Errorf("Deployment type declaration error in 'forj-settings/deployment-types'. '%s' is required", ProDeployType)
+	} else if !pro.Single {
+		return fmt.Errorf("Deployment type declaration error in 'forj-settings/deployment-types'. '%s' must be single", ProDeployType)
+	}
+	return nil
+}
+
+// IsMaintainer returns true if the user can maintain deployments of this type.
+// `groups` are the Forjfile groups, to check users members of a maintainer group.
+func (d *DeploymentTypeStruct) IsMaintainer(user string, groups GroupsStruct) bool {
+	if d == nil || len(d.Maintainers) == 0 {
+		return true
+	}
+	for _, maintainer := range d.Maintainers {
+		if maintainer == user {
+			return true
+		}
+		if group, found := groups[maintainer]; found {
+			for _, member := range group.Members {
+				if member == user {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
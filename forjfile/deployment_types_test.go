@@ -0,0 +1,98 @@
+package forjfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeploymentTypes(t *testing.T) {
+	assert := assert.New(t)
+
+	/*************************************/
+	testCase := "when no deployment types are declared"
+
+	types := defaultDeploymentTypes()
+	assert.NoErrorf(types.validate(), "Expect default types to be valid %s", testCase)
+	assert.Equalf([]string{"DEV", "TEST", "PRO"}, types.Names(), "Expect DEV, TEST and PRO %s", testCase)
+
+	/*************************************/
+	testCase = "when custom deployment types are declared"
+
+	types = DeploymentTypes{
+		{Name: "SANDBOX"},
+		{Name: "STAGING", Single: true, DeployPublish: true},
+		{Name: "PRO", Single: true, Maintainers: []string{"ops", "bob"}},
+		{Name: "DR", Single: true},
+	}
+	assert.NoErrorf(types.validate(), "Expect types to be valid %s", testCase)
+	assert.Truef(types.Rank("STAGING") < types.Rank("PRO"), "Expect STAGING before PRO %s", testCase)
+	assert.Equalf(len(types), types.Rank("UNKNOWN"), "Expect unknown types to be last %s", testCase)
+	if v, found := types.Get("STAGING"); assert.Truef(found, "Expect STAGING to be found %s", testCase) {
+		assert.Truef(v.DeployPublish, "Expect STAGING to require deploy-publish %s", testCase)
+	}
+
+	/*************************************/
+	testCase = "when checking maintainers"
+
+	pro, _ := types.Get("PRO")
+	groups := GroupsStruct{"ops": &GroupStruct{Members: []string{"alice"}}}
+	assert.Truef(pro.IsMaintainer("bob", groups), "Expect bob to be a maintainer %s", testCase)
+	assert.Truef(pro.IsMaintainer("alice", groups), "Expect ops members to be maintainers %s", testCase)
+	assert.Falsef(pro.IsMaintainer("eve", groups), "Expect eve to not be a maintainer %s", testCase)
+	sandbox, _ := types.Get("SANDBOX")
+	assert.Truef(sandbox.IsMaintainer("eve", groups), "Expect anyone to maintain without maintainers %s", testCase)
+
+	/*************************************/
+	testCase = "when deployment types are invalid"
+
+	assert.Errorf(DeploymentTypes{{Name: "DEV"}}.validate(), "Expect PRO to be required %s", testCase)
+	assert.Errorf(DeploymentTypes{{Name: "PRO"}}.validate(), "Expect PRO to be single %s", testCase)
+	assert.Errorf(DeploymentTypes{{Name: "PRO", Single: true}, {Name: "PRO", Single: true}}.validate(), "Expect duplicates to fail %s", testCase)
+	assert.Errorf(DeploymentTypes{{Name: "staging"}, {Name: "PRO", Single: true}}.validate(), "Expect lowercase to fail %s", testCase)
+}
+
+func TestForgeCheckDeploymentRules(t *testing.T) {
+	assert := assert.New(t)
+
+	forge := NewForgeYaml()
+	forge.ForjCore.Init(forge)
+	forge.set_defaults()
+	forge.ForjCore.ForjSettings.DeploymentTypes = DeploymentTypes{
+		{Name: "DEV"},
+		{Name: "STAGING", Single: true, DeployPublish: true},
+		{Name: "PRO", Single: true, Maintainers: []string{"ops", "bob"}},
+	}
+	newDeploy := func(name, deployType string) {
+		deploy := new(DeploymentStruct)
+		deploy.name = name
+		deploy.Type = deployType
+		forge.Deployments[name] = deploy
+	}
+	newDeploy("dev", "DEV")
+	newDeploy("staging", "STAGING")
+	newDeploy("production", "PRO")
+	forge.ForjCore.Set("test", "group", "ops", "role", "admin")
+	forge.ForjCore.Groups["ops"].Members = []string{"alice"}
+	f := new(Forge)
+	f.yaml = forge
+
+	/*************************************/
+	testCase := "when updating a deployment"
+
+	assert.NoErrorf(f.CheckUpdateRules("dev", false), "Expect no error without rules %s", testCase)
+	assert.Errorf(f.CheckUpdateRules("staging", false), "Expect --deploy-publish to be required %s", testCase)
+	assert.NoErrorf(f.CheckUpdateRules("staging", true), "Expect no error with --deploy-publish %s", testCase)
+	assert.NoErrorf(f.CheckUpdateRules("unknown", false), "Expect unknown deployments to be ignored %s", testCase)
+
+	/*************************************/
+	testCase = "when maintaining a deployment"
+
+	assert.NoErrorf(f.CheckMaintainRules("production", "bob"), "Expect bob to be a maintainer %s", testCase)
+	assert.NoErrorf(f.CheckMaintainRules("production", "alice"), "Expect ops members to be maintainers %s", testCase)
+	if err := f.CheckMaintainRules("production", "eve"); assert.Errorf(err, "Expect eve to not be a maintainer %s", testCase) {
+		assert.Containsf(err.Error(), "ops, bob", "Expect maintainers to be listed %s", testCase)
+	}
+	assert.NoErrorf(f.CheckMaintainRules("dev", "eve"), "Expect anyone to maintain without maintainers %s", testCase)
+	assert.NoErrorf(f.CheckMaintainRules("staging", "eve"), "Expect --deploy-publish to not be required %s", testCase)
+}
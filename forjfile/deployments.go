@@ -2,6 +2,7 @@ package forjfile
 
 import (
 	"fmt"
+	"strings"
 )
 
 // Deployments is a collection deployment
@@ -23,7 +24,9 @@ func (d Deployments) GetDeploymentType(deployType string) (v Deployments, found
 // GetDeploymentPROType return the PRO deployment structure
 func (d Deployments) GetDeploymentPROType() (v *DeploymentStruct, err error) {
 
-	if deployObjs, _ := d.GetDeploymentType("PRO"); len(deployObjs) != 1 {
+	if deployObjs, _ := d.GetDeploymentType(ProDeployType); len(deployObjs) == 0 {
+		err = fmt.Errorf("No PRO environment found")
+	} else if len(deployObjs) > 1 {
 		err = fmt.Errorf("Found more than one PRO environment")
 	} else {
 		for k := range deployObjs {
@@ -35,6 +38,52 @@ func (d Deployments) GetDeploymentPROType() (v *DeploymentStruct, err error) {
 	return
 }
 
+// GetDeploymentToCreate return the deployment to create. The PRO one if `deploy` is empty.
+//
+// A deployment can be created only if it is declared with a valid type, one of `types`.
+func (d Deployments) GetDeploymentToCreate(deploy string, types DeploymentTypes) (v *DeploymentStruct, err error) {
+	if deploy == "" {
+		return d.GetDeploymentPROType()
+	}
+	v, found := d.GetADeployment(deploy)
+	if !found {
+		return nil, fmt.Errorf("Unknown deployment environment '%s'. Use one defined in your Forjfile", deploy)
+	}
+	typesList := strings.Join(types.Names(), "|")
+	if v.Type == "" {
+		return nil, fmt.Errorf("Unable to create deployment '%s'. Missing type. Provide at least `Type: (%s)`", deploy, typesList)
+	}
+	if _, found = types.Get(v.Type); !found {
+		return nil, fmt.Errorf("Unable to create deployment '%s'. Invalid type '%s'. Must be one of %s", deploy, v.Type, typesList)
+	}
+	return
+}
+
+// InheritanceChain returns the deployment and the deployments it inherits from, the farthest first.
+//
+// ex: if `staging` inherits from `production`, it returns production and staging.
+func (d Deployments) InheritanceChain(deploy string) (chain []*DeploymentStruct, err error) {
+	names := []string{}
+	for name := deploy; name != ""; {
+		v, found := d.GetADeployment(name)
+		if !found {
+			if len(names) == 0 {
+				return nil, fmt.Errorf("Unable to find deployment '%s'", name)
+			}
+			return nil, fmt.Errorf("Deployment '%s' inherits from an unknown deployment '%s'", names[len(names)-1], name)
+		}
+		for _, known := range names {
+			if known == name {
+				return nil, fmt.Errorf("Deployment inheritance cycle detected: %s", strings.Join(append(names, name), " -> "))
+			}
+		}
+		names = append(names, name)
+		chain = append([]*DeploymentStruct{v}, chain...)
+		name = v.Inherits
+	}
+	return
+}
+
 // GetADeployment return the Deployment Object wanted
 func (d Deployments) GetADeployment(deploy string) (v *DeploymentStruct, found bool) {
 	if deploy == "" {
@@ -0,0 +1,235 @@
+package forjfile
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeploymentsGetDeploymentToCreate(t *testing.T) {
+	assert := assert.New(t)
+
+	newDeploy := func(name, deployType string) (ret *DeploymentStruct) {
+		ret = new(DeploymentStruct)
+		ret.name = name
+		ret.Type = deployType
+		return
+	}
+	deploys := Deployments{
+		"production": newDeploy("production", ProDeployType),
+		"test":       newDeploy("test", testDeployType),
+		"dev":        newDeploy("dev", DevDeployType),
+		"broken":     newDeploy("broken", ""),
+		"other":      newDeploy("other", "OTHER"),
+	}
+	types := defaultDeploymentTypes()
+
+	/*************************************/
+	testCase := "when no deployment is given"
+
+	v, err := deploys.GetDeploymentToCreate("", types)
+	if assert.NoErrorf(err, "Expect no error %s", testCase) {
+		assert.Equalf("production", v.Name(), "Expect the PRO deployment %s", testCase)
+	}
+
+	/*************************************/
+	testCase = "when a TEST deployment is given"
+
+	v, err = deploys.GetDeploymentToCreate("test", types)
+	if assert.NoErrorf(err, "Expect no error %s", testCase) {
+		assert.Equalf("test", v.Name(), "Expect the TEST deployment %s", testCase)
+	}
+
+	/*************************************/
+	testCase = "when the deployment is inconsistent"
+
+	_, err = deploys.GetDeploymentToCreate("unknown", types)
+	assert.Errorf(err, "Expect an error for an unknown deployment %s", testCase)
+	_, err = deploys.GetDeploymentToCreate("broken", types)
+	assert.Errorf(err, "Expect an error for a deployment without type %s", testCase)
+	_, err = deploys.GetDeploymentToCreate("other", types)
+	assert.Errorf(err, "Expect an error for an invalid type %s", testCase)
+
+	/*************************************/
+	testCase = "when the deployment type is declared in forj-settings"
+
+	types = append(types, DeploymentTypeStruct{Name: "OTHER"})
+	v, err = deploys.GetDeploymentToCreate("other", types)
+	if assert.NoErrorf(err, "Expect no error %s", testCase) {
+		assert.Equalf("other", v.Name(), "Expect the OTHER deployment %s", testCase)
+	}
+}
+
+func TestDeploymentsInheritanceChain(t *testing.T) {
+	assert := assert.New(t)
+
+	forge := NewForgeYaml()
+	newDeploy := func(name, inherits string) (ret *DeploymentStruct) {
+		ret = new(DeploymentStruct)
+		ret.name = name
+		ret.Type = ProDeployType
+		ret.Inherits = inherits
+		ret.Details = NewDeployForgeYaml()
+		ret.Details.Init(forge)
+		forge.Deployments[name] = ret
+		return
+	}
+	pro := newDeploy("production", "")
+	staging := newDeploy("staging", "production")
+	newDeploy("dr", "staging")
+	newDeploy("loop-a", "loop-b")
+	newDeploy("loop-b", "loop-a")
+	newDeploy("orphan", "unknown")
+
+	/*************************************/
+	testCase := "when a deployment inherits from other deployments"
+
+	chain, err := forge.Deployments.InheritanceChain("dr")
+	if assert.NoErrorf(err, "Expect no error %s", testCase) && assert.Lenf(chain, 3, "Expect 3 deployments %s", testCase) {
+		assert.Equalf("production", chain[0].Name(), "Expect the farthest deployment first %s", testCase)
+		assert.Equalf("dr", chain[2].Name(), "Expect the deployment last %s", testCase)
+	}
+
+	/*************************************/
+	testCase = "when the inheritance is broken"
+
+	_, err = forge.Deployments.InheritanceChain("loop-a")
+	assert.Errorf(err, "Expect a cycle to be detected %s", testCase)
+	_, err = forge.Deployments.InheritanceChain("orphan")
+	assert.Errorf(err, "Expect an unknown deployment to be detected %s", testCase)
+
+	/*************************************/
+	testCase = "when merging a deployment which inherits from another one"
+
+	pro.Details.Set("test", "repo", "myrepo", FieldRepoTitle, "Production title")
+	pro.Details.Set("test", "repo", "myrepo", FieldRepoFlow, "github-pr")
+	pro.Details.Set("test", "projects", "myproject", "owner", "ops")
+	pro.Details.Set("test", "projects", "myproject", "size", "large")
+	staging.Details.Set("test", "repo", "myrepo", FieldRepoTitle, "Staging title")
+	staging.Details.Set("test", "projects", "myproject", "size", "small")
+	pro.Details.Repos.attachToDeploy("production")
+
+	f := new(Forge)
+	f.yaml = forge
+	result, err := f.MergeFromDeployment("staging")
+	if !assert.NoErrorf(err, "Expect no error %s", testCase) {
+		return
+	}
+	v, _, _ := result.GetString("repo", "myrepo", FieldRepoTitle)
+	assert.Equalf("Staging title", v, "Expect the deployment value to win %s", testCase)
+	v, _, _ = result.GetString("repo", "myrepo", FieldRepoFlow)
+	assert.Equalf("github-pr", v, "Expect inherited values %s", testCase)
+	v, _, _ = result.GetString("projects", "myproject", "owner")
+	assert.Equalf("ops", v, "Expect inherited plugin object keys %s", testCase)
+	v, _, _ = result.GetString("projects", "myproject", "size")
+	assert.Equalf("small", v, "Expect the deployment plugin object key to win %s", testCase)
+	assert.Equalf("staging", result.Repos["myrepo"].deployment, "Expect the repo to be deployed by the merged deployment %s", testCase)
+
+	/*************************************/
+	testCase = "when a deployment has been merged"
+
+	v, _, _ = pro.Details.GetString("repo", "myrepo", FieldRepoTitle)
+	assert.Equalf("Production title", v, "Expect the inherited deployment to be unchanged %s", testCase)
+	assert.Equalf("production", pro.Details.Repos["myrepo"].deployment, "Expect the inherited repo deployment to be unchanged %s", testCase)
+	v, _, _ = pro.Details.GetString("projects", "myproject", "size")
+	assert.Equalf("large", v, "Expect the inherited plugin object to be unchanged %s", testCase)
+	assert.Falsef(pro.Details.Repos["myrepo"] == result.Repos["myrepo"], "Expect the repo to be copied %s", testCase)
+}
+
+func TestForgeSaveAfterMerge(t *testing.T) {
+	assert := assert.New(t)
+
+	infraPath, err := ioutil.TempDir("", "forjj-merge")
+	if !assert.NoError(err, "Expect temporary directory to be created") {
+		return
+	}
+	defer os.RemoveAll(infraPath)
+	write := func(file, data string) {
+		file = path.Join(infraPath, file)
+		os.MkdirAll(path.Dir(file), 0755)
+		assert.NoError(ioutil.WriteFile(file, []byte(data), 0644), "Expect file %s to be written", file)
+	}
+	read := func(file string) string {
+		data, _ := ioutil.ReadFile(path.Join(infraPath, file))
+		return string(data)
+	}
+	master := `deployments:
+  production:
+    type: PRO
+repositories:
+  myrepo:
+    title: Master title
+`
+	write("Forjfile", master)
+	write("deployments/production/Forjfile", `repositories:
+  myrepo:
+    title: Prod title
+`)
+
+	/*************************************/
+	testCase := "when the Forjfile is saved after the deployment merge"
+
+	f := new(Forge)
+	assert.NoErrorf(f.SetInfraPath(infraPath, true), "Expect infra path to be set %s", testCase)
+	if _, err = f.Load("production"); !assert.NoErrorf(err, "Expect Forjfile to be loaded %s", testCase) {
+		return
+	}
+	assert.NoErrorf(f.BuildForjfileInMem(), "Expect the deployment to be merged %s", testCase)
+	v, _, _ := f.InMemForjfile().GetString("repo", "myrepo", FieldRepoTitle)
+	assert.Equalf("Prod title", v, "Expect the deployment value in memory %s", testCase)
+	assert.NoErrorf(f.Save(), "Expect Forjfile to be saved %s", testCase)
+	assert.Equalf(master, read("Forjfile"), "Expect the master Forjfile to be unchanged %s", testCase)
+	assert.Containsf(read("deployments/production/Forjfile"), "title: Prod title", "Expect the deployment Forjfile to be unchanged %s", testCase)
+}
+
+func TestForgeSourcesRevision(t *testing.T) {
+	assert := assert.New(t)
+
+	infraPath, err := ioutil.TempDir("", "forjj-revision")
+	if !assert.NoError(err, "Expect temporary directory to be created") {
+		return
+	}
+	defer os.RemoveAll(infraPath)
+	write := func(file, data string) {
+		file = path.Join(infraPath, file)
+		os.MkdirAll(path.Dir(file), 0755)
+		assert.NoError(ioutil.WriteFile(file, []byte(data), 0644), "Expect file %s to be written", file)
+	}
+	load := func() string {
+		f := new(Forge)
+		assert.NoError(f.SetInfraPath(infraPath, true), "Expect infra path to be set")
+		_, err := f.Load("production")
+		assert.NoError(err, "Expect Forjfile to be loaded")
+		return f.SourcesRevision()
+	}
+	write("Forjfile", `deployments:
+  production:
+    type: PRO
+repositories:
+  myrepo:
+    title: Master title
+`)
+	write("deployments/production/Forjfile", `repositories:
+  myrepo:
+    title: Prod title
+`)
+
+	/*************************************/
+	testCase := "when Forjfiles are loaded twice"
+
+	revision := load()
+	assert.NotEmptyf(revision, "Expect a revision %s", testCase)
+	assert.Equalf(revision, load(), "Expect the same revision %s", testCase)
+
+	/*************************************/
+	testCase = "when the deployment Forjfile is updated"
+
+	write("deployments/production/Forjfile", `repositories:
+  myrepo:
+    title: New prod title
+`)
+	assert.NotEqualf(revision, load(), "Expect a new revision %s", testCase)
+}
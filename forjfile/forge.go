@@ -1,11 +1,15 @@
 package forjfile
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"forjj/utils"
 	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/forj-oss/forjj-modules/trace"
 	"github.com/forj-oss/goforjj"
@@ -17,6 +21,9 @@ type ForjfileTmpl struct {
 	file_loaded string
 	Workspace   WorkspaceStruct // See workspace.go
 	yaml        ForgeYaml
+	layers      []string            // Forjfile models merged, in order.
+	objects     map[string][]string // Forjfile models defining each object instance, by object/name.
+	deployments bool                // True if Forjfile models define deployments.
 }
 
 // Forge is the Memory expand of a repository Forjfile.
@@ -30,6 +37,9 @@ type Forge struct {
 	file_name        string // Relative path to the Forjfile.
 	yaml             *ForgeYaml
 	inMem            *DeployForgeYaml
+	docs             map[string]*yamlDocument // Forjfiles loaded, by absolute path. Used to save them back.
+	sums             map[string]string        // Checksum of Forjfiles loaded, by absolute path. See SourcesRevision.
+	flowLoader       func(flow string) error  // Used to validate flows references. See references.go
 }
 
 const (
@@ -39,8 +49,6 @@ const (
 	ProDeployType  = "PRO"
 )
 
-// TODO: Load multiple templates that will be merged.
-
 // LoadTmpl Search for Forjfile in `aPath` and load it.
 // This file combines the Forjfile in the infra repository and the Workspace
 func LoadTmpl(aPath string) (f *ForjfileTmpl, loaded bool, err error) {
@@ -78,22 +86,11 @@ func LoadTmpl(aPath string) (f *ForjfileTmpl, loaded bool, err error) {
 		file = fi
 	}
 
-	f = new(ForjfileTmpl)
-
-	f.file_loaded = file
-	if e := yaml.Unmarshal(yaml_data, &f.yaml); e != nil {
-		err = fmt.Errorf("Unable to load %s. %s", file, e)
+	if f, err = newForjfileTmpl(file, yaml_data); err != nil {
 		return
 	}
-
-	f.Workspace = f.yaml.ForjCore.LocalSettings
-	// Setting internals and some predefined objects
-	f.yaml.set_defaults()
 	loaded = true
 
-	// Setting default values found in Forjfile/forj-settings/default/...
-	f.yaml.defineDefaults(false) // Do not warn if default are set.
-
 	gotrace.Trace("Forjfile model '%s' has been loaded.", file)
 	return
 }
@@ -213,6 +210,10 @@ func (f *Forge) Load(deployTo string) (loaded bool, err error) {
 	if _, err = f.load(&f.yaml, aPath); err != nil {
 		return
 	}
+	f.yaml.ForjCore.setFileSources(aPath, f.document(aPath))
+	if err = f.loadIncludes(&f.yaml.ForjCore, aPath); err != nil {
+		return
+	}
 	if bAlreadyLoaded {
 		gotrace.Warning("Both, a Forjfile and a Forjfile model were loaded. The model has been ignored.")
 	}
@@ -226,6 +227,10 @@ func (f *Forge) Load(deployTo string) (loaded bool, err error) {
 		if _, err = f.load(deployDetail, aPath); err != nil {
 			return
 		}
+		deployDetail.setFileSources(aPath, f.document(aPath))
+		if err = f.loadIncludes(deployDetail, aPath); err != nil {
+			return
+		}
 		deployDetail.Repos.attachToDeploy(deployName)
 		if deployData.Type == "PRO" {
 			f.attachInfraToDeployment(deployName)
@@ -295,6 +300,7 @@ func (f *Forge) load(deployData interface{}, aPath string) (loaded bool, err err
 		return
 	}
 	loaded = true
+	f.keepDocument(file, yaml_data)
 
 	f.yaml.set_defaults()
 	gotrace.Trace("%s loaded.", aPath)
@@ -308,29 +314,48 @@ func (f *Forge) BuildForjfileInMem() (err error) {
 }
 
 // MergeFromDeployment provide a merge between Master and Deployment Forjfile.
+// If the deployment inherits from other deployments, their Forjfiles are merged before it.
 func (f *Forge) MergeFromDeployment(deployTo string) (result *DeployForgeYaml, err error) {
 	if f == nil {
 		return nil, fmt.Errorf("Forge is nil")
 	}
-	deploy, found := f.GetADeployment(deployTo)
-	if !found {
-		return nil, fmt.Errorf("Unable to find deployment '%s'", deployTo)
+	chain, err := f.yaml.Deployments.InheritanceChain(deployTo)
+	if err != nil {
+		return nil, err
 	}
 	forge := NewForgeYaml()
+	forge.master = f.yaml
 
-	// Keep list of deployment definition, but no details.
+	// Keep list of deployment definition, but no details. Details are given by the master Forjfile.
 	for deployName, deploy := range f.yaml.Deployments {
 		newDeploy := new(DeploymentStruct)
 		newDeploy.DeploymentCoreStruct = deploy.DeploymentCoreStruct
 		forge.Deployments[deployName] = newDeploy
 	}
 
+	// Objects are copied by the merge. So the master and deployments Forjfiles are never updated.
 	result = &forge.ForjCore
 	if err = result.mergeFrom(&f.yaml.ForjCore); err != nil {
 		return nil, fmt.Errorf("Unable to load the master forjfile. %s", err)
 	}
-	if err = result.mergeFrom(deploy.Details); err != nil {
-		return nil, fmt.Errorf("Unable to merge the Deployment forjfile to master one. %s", err)
+	// Objects copied are attached to the new forge, before merging deployments values in.
+	result.initDefaults(forge)
+	// Deployments inherited first. The deployment Forjfile is merged last.
+	for _, deploy := range chain {
+		if err = result.mergeFrom(deploy.Details); err != nil {
+			return nil, fmt.Errorf("Unable to merge the '%s' Deployment forjfile to master one. %s", deploy.Name(), err)
+		}
+	}
+	// Repositories inherited from another deployment Forjfile of the chain are deployed by this one.
+	for _, deploy := range chain {
+		if deploy.Details == nil {
+			continue
+		}
+		for name := range deploy.Details.Repos {
+			if repo := result.Repos[name]; repo != nil && repo.deployment == deploy.Name() {
+				repo.deployment = deployTo
+			}
+		}
 	}
 	result.deployTo = deployTo
 	result.initDefaults(forge)
@@ -466,6 +491,17 @@ func (f *Forge) Forjfiles_name() (ret []string) {
 	for name := range f.yaml.Deployments {
 		ret = append(ret, path.Join("deployments", name))
 	}
+	included := append([]string{}, f.yaml.ForjCore.included.files...)
+	for _, deploy := range f.yaml.Deployments {
+		if deploy.Details != nil {
+			included = append(included, deploy.Details.included.files...)
+		}
+	}
+	for _, file := range included {
+		if rel, err := filepath.Rel(f.infra_path, file); err == nil && !strings.HasPrefix(rel, "..") {
+			ret = append(ret, rel)
+		}
+	}
 	return
 }
 
@@ -483,7 +519,12 @@ func (f *Forge) save(infraPath string) error {
 	}
 
 	file := path.Join(infraPath, f.Forjfile_name())
-	yaml_data, err := yaml.Marshal(f.yaml)
+	master := *f.yaml
+	if !f.yaml.ForjCore.ForjSettings.is_template {
+		// Objects defined in included files are saved in those files.
+		master.ForjCore = f.yaml.ForjCore.withoutIncluded()
+	}
+	yaml_data, err := yaml.Marshal(&master)
 	if err != nil {
 		return err
 	}
@@ -494,13 +535,16 @@ func (f *Forge) save(infraPath string) error {
 		}
 	}
 
-	if err := ioutil.WriteFile(file, yaml_data, 0644); err != nil {
+	if err := f.writeForjfile(file, yaml_data); err != nil {
 		return err
 	}
 	gotrace.Trace("File name saved: %s", file)
 	if f.yaml.ForjCore.ForjSettings.is_template {
 		return nil
 	}
+	if err := f.saveIncluded(&f.yaml.ForjCore); err != nil {
+		return err
+	}
 	for name, deployTo := range f.yaml.Deployments {
 		filepath := path.Join(infraPath, "deployments", name)
 
@@ -511,7 +555,8 @@ func (f *Forge) save(infraPath string) error {
 			deployTo.Details = new(DeployForgeYaml)
 		}
 
-		yaml_data, err = yaml.Marshal(deployTo.Details)
+		deployData := deployTo.Details.withoutIncluded()
+		yaml_data, err = yaml.Marshal(&deployData)
 		if err != nil {
 			return err
 		}
@@ -527,16 +572,77 @@ func (f *Forge) save(infraPath string) error {
 			}
 		}
 
-		if err := ioutil.WriteFile(file, yaml_data, 0644); err != nil {
+		if err := f.writeForjfile(file, yaml_data); err != nil {
 			return err
 		}
 		gotrace.Trace("Deployment file name saved: %s", file)
+		if err := f.saveIncluded(deployTo.Details); err != nil {
+			return err
+		}
 
 	}
 
 	return nil
 }
 
+// keepDocument keeps the Forjfile loaded to save it back with comments, keys order and anchors.
+func (f *Forge) keepDocument(file string, data []byte) {
+	if f.docs == nil {
+		f.docs = make(map[string]*yamlDocument)
+	}
+	f.docs[file] = newYamlDocument(data)
+	if f.sums == nil {
+		f.sums = make(map[string]string)
+	}
+	f.sums[file] = fmt.Sprintf("%x", sha256.Sum256(data))
+}
+
+// SourcesRevision returns a checksum of all Forjfiles loaded (master, included and deployment Forjfiles),
+// as read on the disk. Any change in those files, committed or not, gives a different revision.
+func (f *Forge) SourcesRevision() string {
+	files := make([]string, 0, len(f.sums))
+	for file := range f.sums {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	sum := sha256.New()
+	for _, file := range files {
+		fmt.Fprintf(sum, "%s %s\n", f.sums[file], file)
+	}
+	return fmt.Sprintf("%x", sum.Sum(nil))
+}
+
+// document returns the Forjfile document kept when aPath was loaded. nil if not found.
+func (f *Forge) document(aPath string) *yamlDocument {
+	absFile, err := utils.Abs(aPath)
+	if err != nil {
+		return nil
+	}
+	return f.docs[absFile]
+}
+
+// writeForjfile writes a Forjfile. If the Forjfile was loaded, only the updated entries are changed.
+func (f *Forge) writeForjfile(file string, data []byte) error {
+	absFile, err := utils.Abs(file)
+	if err != nil {
+		return err
+	}
+	if doc, found := f.docs[absFile]; found && doc != nil {
+		if updated, err := doc.update(data); err != nil {
+			gotrace.Warning("Unable to keep '%s' comments and order. %s", file, err)
+		} else {
+			data = updated
+		}
+	}
+
+	if err = ioutil.WriteFile(file, data, 0644); err != nil {
+		return err
+	}
+	f.keepDocument(absFile, data)
+	return nil
+}
+
 // SaveTmpl provide Forjfile model export from a Forge.
 func SaveTmpl(aPath string, f *Forge) error {
 	forge := new(Forge)
@@ -732,6 +838,31 @@ func (f *Forge) Remove(object, name, key string) {
 	forge.Remove(object, name, key)
 }
 
+// HasInstance returns true if the object instance is defined in the master Forjfile.
+func (f *Forge) HasInstance(object, name string) bool {
+	if !f.Init() {
+		return false
+	}
+	return f.yaml.ForjCore.HasInstance(object, name)
+}
+
+// RemoveInstance removes an object instance from the master Forjfile.
+// It returns false if the instance was not found.
+func (f *Forge) RemoveInstance(object, name string) bool {
+	if !f.Init() {
+		return false
+	}
+	return f.yaml.ForjCore.RemoveInstance(object, name)
+}
+
+// RenameInstance renames a repository or an application in the master Forjfile.
+func (f *Forge) RenameInstance(object, name, newName string) error {
+	if !f.Init() {
+		return fmt.Errorf("Forge is nil")
+	}
+	return f.yaml.ForjCore.RenameInstance(object, name, newName)
+}
+
 // SetTo permit to store the data in one of the Forjfile representative.
 // Compare to Set, which can use the global or the merged data.
 func (f *Forge) SetTo(dest, source, object, name, key, value string) {
@@ -848,52 +979,63 @@ func (f *Forge) GetADeployment(deploy string) (v *DeploymentStruct, found bool)
 
 // Validate check if the information in the Forjfile are coherent or not and if code respect some basic rules.
 // Validate do not check default values. So, validate can be executed before setting driver default values (forj.ScanAndSetObjectData)
+// It returns all errors found. Warnings are displayed.
 func (f *Forge) Validate() error {
+	report := NewValidationReport()
+	f.ValidateTo(report)
+	for _, warning := range report.Warnings() {
+		gotrace.Warning("%s", warning)
+	}
+	return report.Err()
+}
+
+// ValidateTo reports every issue found in the Forjfile loaded and the deployments declaration.
+func (f *Forge) ValidateTo(report *ValidationReport) {
 	forge := f.selectCore()
 	if forge == nil {
-		return fmt.Errorf("No Forjfile Data to validate")
+		report.AddError("", "No Forjfile Data to validate")
+		return
 	}
 
-	// ForjSettingsStruct.More
+	f.validateCore(forge, report)
+	f.validateDeployments(report)
+}
 
-	// Repo connected to a valid deployment
-	for _, repo := range forge.Repos {
-		if v := repo.Deployment; v != "" {
-			if deploy, found := f.yaml.Deployments[v]; !found {
-				return fmt.Errorf("Repo '%s': Deployment '%s' doesn't exist. Check deployments section of your Forjfile", repo.name, deploy.name)
-			}
-		}
-	}
+// ValidateDeployment reports every issue found in a deployment, merged with the master Forjfile.
+// It returns the merged Forjfile validated, or nil if the merge failed.
+func (f *Forge) ValidateDeployment(deploy string, report *ValidationReport) (forge *DeployForgeYaml) {
+	report.SetDeployment(deploy)
+	defer report.SetDeployment("")
 
-	// check if we declared deployment repository in deploy Forjfile.
-	for _, deploy := range f.GetDeployments() {
-		if deploy.Details == nil || deploy.Details.Repos == nil {
-			continue
-		}
-		for _, repo := range deploy.Details.Repos {
-			if repo.Deployment != "" {
-				return fmt.Errorf("Unable to declare a deployment Repository in the deployment %s/Forjfile. Remove 'deployment' entry for repo %s", deploy.Name(), repo.name)
-			}
-		}
-		if deploy.Details.Infra != nil {
-			gotrace.Warning("Found infra data from deployment '%s'. This definition is ignored. Move it to your main Forjfile.", deploy.Desc)
-		}
+	forge, err := f.MergeFromDeployment(deploy)
+	if err != nil {
+		report.AddError("", "%s", err)
+		return nil
 	}
+	f.validateCore(forge, report)
+	return
+}
+
+// validateCore reports issues found in a Forjfile (master or merged)
+func (f *Forge) validateCore(forge *DeployForgeYaml, report *ValidationReport) {
+	// ForjSettingsStruct.More
 
 	// RepoStruct.More (infra : Repos)
 
 	// AppYamlStruct.More
 
 	// Repository apps connection
-	for _, repo := range forge.Repos {
+	for name, repo := range forge.Repos {
 		if repo.Apps == nil {
 			continue
 		}
 
 		for relAppName, appName := range repo.Apps {
+			if forge.Apps[appName] == nil {
+				continue // Reported by validateReferences.
+			}
 			if _, err := repo.SetInternalRelApp(relAppName, appName); err != nil {
-				name, _ := repo.GetString("name")
-				return fmt.Errorf("Repo '%s' has an invalid Application reference '%s: %s'. %s", name, relAppName, appName, err)
+				report.AddError("repo/"+name+"/apps", "Invalid Application reference '%s: %s'. %s", relAppName, appName, err)
 			}
 		}
 	}
@@ -904,30 +1046,63 @@ func (f *Forge) Validate() error {
 
 	// ForgeYaml.More
 
+	f.validateReferences(forge, report)
+}
+
+// validateDeployments reports issues found in the deployments declaration and in deployment Forjfiles.
+func (f *Forge) validateDeployments(report *ValidationReport) {
+	// check if we declared deployment repository in deploy Forjfile.
+	for _, deploy := range f.GetDeployments() {
+		if deploy.Details == nil || deploy.Details.Repos == nil {
+			continue
+		}
+		for _, repo := range deploy.Details.Repos {
+			if repo.Deployment != "" {
+				report.AddError("deployments/"+deploy.Name()+"/repo/"+repo.name, "Unable to declare a deployment Repository in the deployment %s/Forjfile. Remove 'deployment' entry", deploy.Name())
+			}
+		}
+		if deploy.Details.Infra.isDefined() {
+			report.AddWarning("deployments/"+deploy.Name()+"/infra", "Found infra data from deployment '%s'. This definition is ignored. Move it to your main Forjfile.", deploy.Name())
+		}
+	}
+
 	// DeploymentStruct
-	pro := false
-	devDefault := forge.ForjSettings.Default.getDevDeploy()
+	deployTypes := f.DeploymentTypes()
+	if err := deployTypes.validate(); err != nil {
+		report.AddError("settings/deployment-types", "%s", err)
+		return
+	}
+	typesList := strings.Join(deployTypes.Names(), "|")
+	single := make(map[string]string)
+	devDefault := f.yaml.ForjCore.ForjSettings.Default.getDevDeploy()
 	devDefaultFound := false
 	for name, deploy := range f.yaml.Deployments {
+		path := "deployments/" + name
 		if deploy.Type == "" {
-			return fmt.Errorf("Deployment declaration error in '%s'. Missing type. Provide at least `Type: (PRO|TEST|DEV)`", name)
+			report.AddError(path, "Deployment declaration error in '%s'. Missing type. Provide at least `Type: (%s)`", name, typesList)
+			continue
 		}
-		if deploy.Type == ProDeployType {
-			if pro {
-				return fmt.Errorf("Deployment declaration error in '%s'. You cannot have more than 1 deployment of type 'PRO'. Please fix it", name)
-			} else {
-				pro = true
+		deployType, found := deployTypes.Get(deploy.Type)
+		if !found {
+			report.AddError(path, "Deployment declaration error in '%s'. Invalid type '%s'. Must be one of %s", name, deploy.Type, typesList)
+			continue
+		}
+		if deployType.Single {
+			if other, found := single[deploy.Type]; found {
+				report.AddError(path, "Deployment declaration error in '%s'. You cannot have more than 1 deployment of type '%s' ('%s' is already declared). Please fix it", name, deploy.Type, other)
 			}
+			single[deploy.Type] = name
+		}
+		if _, err := f.yaml.Deployments.InheritanceChain(name); err != nil {
+			report.AddError(path, "Deployment declaration error in '%s'. %s", name, err)
 		}
 		if deploy.Type == DevDeployType && devDefault == deploy.name {
 			devDefaultFound = true
 		}
 	}
 	if devDefault != "" && !devDefaultFound {
-		return fmt.Errorf("Deployment declaration error in '%s'. '%s' is not a valid default DEV deployment name. Please fix it", "forj-settings/default/dev-deploy", devDefault)
+		report.AddError("settings/default/dev-deploy", "Deployment declaration error in '%s'. '%s' is not a valid default DEV deployment name. Please fix it", "forj-settings/default/dev-deploy", devDefault)
 	}
-
-	return nil
 }
 
 // GetDeployments returns all deployments.
@@ -946,6 +1121,72 @@ func (f *Forge) GetDeploymentType(deployType string) (Deployments, bool) {
 	return f.yaml.Deployments.GetDeploymentType(deployType)
 }
 
+// GetDeploymentToCreate return the deployment to create. The PRO one if `deploy` is empty.
+func (f *Forge) GetDeploymentToCreate(deploy string) (v *DeploymentStruct, err error) {
+	return f.yaml.Deployments.GetDeploymentToCreate(deploy, f.DeploymentTypes())
+}
+
+// DeploymentTypes returns deployment types declared in forj-settings/deployment-types, in promotion order.
+// If none are declared, it returns DEV, TEST and PRO.
+func (f *Forge) DeploymentTypes() DeploymentTypes {
+	if f == nil || f.yaml == nil {
+		return defaultDeploymentTypes()
+	}
+	return f.yaml.deploymentTypes()
+}
+
+// GetDeploymentTypeRules returns the rules of the deployment type.
+func (f *Forge) GetDeploymentTypeRules(deployType string) (*DeploymentTypeStruct, bool) {
+	return f.DeploymentTypes().Get(deployType)
+}
+
+// IsMaintainer returns true if the user can maintain the deployment, following its deployment type rules.
+func (f *Forge) IsMaintainer(deploy *DeploymentStruct, user string) bool {
+	if deploy == nil {
+		return false
+	}
+	deployType, _ := f.GetDeploymentTypeRules(deploy.Type)
+	return deployType.IsMaintainer(user, f.yaml.ForjCore.Groups)
+}
+
+// CheckUpdateRules verifies an update of the deployment respects its deployment type rules.
+// The deployment type can require --deploy-publish.
+func (f *Forge) CheckUpdateRules(deployName string, deployPublish bool) error {
+	deploy, deployType, found := f.deploymentRules(deployName)
+	if !found {
+		return nil
+	}
+	if deployType.DeployPublish && !deployPublish {
+		return fmt.Errorf("Deployment '%s' (%s) must be updated with --deploy-publish", deploy.Name(), deploy.Type)
+	}
+	return nil
+}
+
+// CheckMaintainRules verifies the user can maintain the deployment, following its deployment type rules.
+//
+// This check is advisory: user is compared with Forjfile users and group members, but nothing proves who
+// runs forjj. It is not an access control.
+func (f *Forge) CheckMaintainRules(deployName, user string) error {
+	deploy, deployType, found := f.deploymentRules(deployName)
+	if !found {
+		return nil
+	}
+	if !f.IsMaintainer(deploy, user) {
+		return fmt.Errorf("'%s' is not allowed to maintain deployment '%s' (%s). Maintainers are: %s", user, deploy.Name(), deploy.Type, strings.Join(deployType.Maintainers, ", "))
+	}
+	return nil
+}
+
+// deploymentRules returns the deployment and its deployment type rules.
+// found is false if the deployment or its type is unknown. (reported by the Forjfile validation)
+func (f *Forge) deploymentRules(deployName string) (deploy *DeploymentStruct, deployType *DeploymentTypeStruct, found bool) {
+	if deploy, found = f.GetADeployment(deployName); !found {
+		return
+	}
+	deployType, found = f.GetDeploymentTypeRules(deploy.Type)
+	return
+}
+
 // GetDeploymentPROType return the PRO deployment structure
 func (f *Forge) GetDeploymentPROType() (v *DeploymentStruct, err error) {
 	return f.yaml.Deployments.GetDeploymentPROType()
@@ -7,6 +7,7 @@ import (
 // ForgeYaml represents the master Forjfile or a piece of the Forjfile model.
 type ForgeYaml struct {
 	updated     bool
+	master      *ForgeYaml // Master Forjfile a deployment Forjfile is merged from. See Forge.MergeFromDeployment
 	Deployments Deployments
 	ForjCore    DeployForgeYaml `yaml:",inline"`
 }
@@ -67,6 +68,14 @@ func (f *ForgeYaml) defineDefaults(warn bool) {
 	}
 }
 
+// deploymentTypes returns the deployment types declared in the master Forjfile, or the default ones.
+func (f *ForgeYaml) deploymentTypes() DeploymentTypes {
+	if types := f.ForjCore.ForjSettings.DeploymentTypes; len(types) > 0 {
+		return types
+	}
+	return defaultDeploymentTypes()
+}
+
 // set_defaults
 // - set forge in all structures
 // - Define a basic Deployment with just 'production' entry
@@ -40,9 +40,18 @@ func (s *ForjSettingsStruct) mergeFrom(from *ForjSettingsStruct) {
 	if from == nil {
 		return
 	}
+	if len(from.DeploymentTypes) > 0 {
+		s.DeploymentTypes = from.DeploymentTypes
+	}
 	for _, instance := range []string{"default", "default-repo-apps", "noinstance"} {
 		for _, flag := range from.Flags() {
 			if v, found, source := from.Get(instance, flag); found {
+				switch instance {
+				case "default":
+					s.Default.sources = s.Default.sources.Merge(flag, from.Default.sources)
+				case "noinstance":
+					s.sources = s.sources.Merge(flag, from.sources)
+				}
 				s.Set(source, instance, flag, v.GetString())
 			}
 		}
@@ -53,7 +62,9 @@ func (s *ForjSettingsStruct) mergeFrom(from *ForjSettingsStruct) {
 type ForjSettingsStructTmpl struct {
 	Default  DefaultSettingsStruct
 	RepoApps DefaultRepoAppSettingsStruct `yaml:"default-repo-apps,omitempty"` // Default repo Application
-	More     map[string]string            `yaml:",inline"`
+	// Deployment types, in promotion order. See deployment_types.go
+	DeploymentTypes DeploymentTypes   `yaml:"deployment-types,omitempty"`
+	More            map[string]string `yaml:",inline"`
 }
 
 func (f *ForjSettingsStruct) MarshalYAML() (interface{}, error) {
@@ -130,9 +141,11 @@ func (s *ForjSettingsStruct) Set(source, instance, key, value string) {
 	case "organization":
 		s.Organization = value
 		s.forge.dirty()
-		return
 	default:
-		if v, found := s.More[key]; found && v != value {
+		if v, found := s.More[key]; !found || v != value {
+			if s.More == nil {
+				s.More = make(map[string]string)
+			}
 			s.forge.dirty()
 			s.More[key] = value
 		}
@@ -0,0 +1,229 @@
+package forjfile
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/forj-oss/forjj-modules/trace"
+	"gopkg.in/yaml.v2"
+)
+
+// TmplObject reports the Forjfile models which define an object instance.
+type TmplObject struct {
+	Object string   `json:"object"`
+	Name   string   `json:"name"`
+	Layers []string `json:"layers"` // Forjfile models, in merge order. The last one wins.
+}
+
+// LoadTmpls loads several Forjfile models and merges them in order into a single model.
+//
+// A model is a path to a directory containing a Forjfile or an http(s) URL to a Forjfile.
+// Objects defined by several models are merged. The last model wins on each value.
+func LoadTmpls(models []string) (f *ForjfileTmpl, loaded bool, err error) {
+	for _, model := range models {
+		var (
+			layer *ForjfileTmpl
+			found bool
+		)
+		if isModelURL(model) {
+			layer, found, err = loadTmplURL(model)
+		} else {
+			layer, found, err = LoadTmpl(model)
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		if !found {
+			if len(models) > 1 {
+				gotrace.Warning("No Forjfile model found in '%s'. Ignored.", model)
+			}
+			continue
+		}
+		if f == nil {
+			f = layer
+			continue
+		}
+		if err = f.merge(layer); err != nil {
+			return nil, false, fmt.Errorf("Unable to merge the Forjfile model '%s'. %s", model, err)
+		}
+	}
+	if f == nil {
+		return
+	}
+	loaded = true
+
+	if len(f.layers) > 1 {
+		// Merged objects must refer to the merged Forjfile.
+		f.yaml.set_defaults()
+		f.yaml.defineDefaults(false)
+		gotrace.Trace("Forjfile models '%s' have been merged.", f.file_loaded)
+	}
+	return
+}
+
+// modelClient downloads Forjfile models. A model server which does not answer must not block forjj.
+var modelClient = &http.Client{Timeout: 30 * time.Second}
+
+// isModelURL returns true if the model is an http(s) URL.
+func isModelURL(model string) bool {
+	return strings.HasPrefix(model, "http://") || strings.HasPrefix(model, "https://")
+}
+
+// loadTmplURL downloads a Forjfile model.
+func loadTmplURL(url string) (f *ForjfileTmpl, loaded bool, err error) {
+	resp, err := modelClient.Get(url)
+	if err != nil {
+		return nil, false, fmt.Errorf("Unable to download the Forjfile model '%s'. %s", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("Unable to download the Forjfile model '%s'. %s", url, resp.Status)
+	}
+	yaml_data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, fmt.Errorf("Unable to download the Forjfile model '%s'. %s", url, err)
+	}
+
+	if f, err = newForjfileTmpl(url, yaml_data); err != nil {
+		return
+	}
+	loaded = true
+
+	gotrace.Trace("Forjfile model '%s' has been loaded.", url)
+	return
+}
+
+// newForjfileTmpl creates a Forjfile model from the Forjfile data.
+func newForjfileTmpl(file string, yaml_data []byte) (f *ForjfileTmpl, err error) {
+	f = new(ForjfileTmpl)
+
+	f.file_loaded = file
+	if e := yaml.Unmarshal(yaml_data, &f.yaml); e != nil {
+		return nil, fmt.Errorf("Unable to load %s. %s", file, e)
+	}
+	f.deployments = (len(f.yaml.Deployments) > 0)
+
+	f.Workspace = f.yaml.ForjCore.LocalSettings
+	// Setting internals and some predefined objects
+	f.yaml.set_defaults()
+	f.yaml.ForjCore.setFileSources(file, newYamlDocument(yaml_data))
+
+	// Setting default values found in Forjfile/forj-settings/default/...
+	f.yaml.defineDefaults(false) // Do not warn if default are set.
+
+	f.layers = []string{file}
+	f.objects = make(map[string][]string)
+	for _, key := range f.instances() {
+		f.objects[key] = []string{file}
+	}
+	return
+}
+
+// merge adds a Forjfile model on top of this one.
+func (f *ForjfileTmpl) merge(from *ForjfileTmpl) error {
+	core, fromCore := &f.yaml.ForjCore, &from.yaml.ForjCore
+	if err := core.mergeFrom(fromCore); err != nil {
+		return err
+	}
+	if fromCore.Infra != nil {
+		core.Infra = core.Infra.mergeFrom(fromCore.Infra)
+	}
+	f.Workspace.mergeFrom(from.Workspace)
+
+	if from.deployments {
+		if !f.deployments { // Remove the default deployment created if the model has none.
+			f.yaml.Deployments = make(Deployments)
+			f.deployments = true
+		}
+		for name, deploy := range from.yaml.Deployments {
+			current, found := f.yaml.Deployments[name]
+			if !found {
+				f.yaml.Deployments[name] = deploy
+				continue
+			}
+			if deploy.Desc != "" {
+				current.Desc = deploy.Desc
+			}
+			if deploy.Type != "" {
+				current.Type = deploy.Type
+			}
+			for key, value := range deploy.Pars {
+				if current.Pars == nil {
+					current.Pars = make(map[string]string)
+				}
+				current.Pars[key] = value
+			}
+			if current.Details == nil {
+				current.Details = deploy.Details
+			} else if err := current.Details.mergeFrom(deploy.Details); err != nil {
+				return fmt.Errorf("Unable to merge deployment '%s'. %s", name, err)
+			}
+		}
+	}
+
+	for _, key := range from.instances() {
+		f.objects[key] = append(f.objects[key], from.layers...)
+	}
+	f.layers = append(f.layers, from.layers...)
+	f.file_loaded = strings.Join(f.layers, ", ")
+	return nil
+}
+
+// instances returns the list of object instances defined, as object/name.
+func (f *ForjfileTmpl) instances() (ret []string) {
+	core := &f.yaml.ForjCore
+	for name := range core.Repos {
+		ret = append(ret, "repo/"+name)
+	}
+	for name := range core.Apps {
+		ret = append(ret, "app/"+name)
+	}
+	for name := range core.Users {
+		ret = append(ret, "user/"+name)
+	}
+	for name := range core.Groups {
+		ret = append(ret, "group/"+name)
+	}
+	for object, instances := range core.More {
+		for name := range instances {
+			ret = append(ret, object+"/"+name)
+		}
+	}
+	if f.deployments {
+		for name := range f.yaml.Deployments {
+			ret = append(ret, "deployment/"+name)
+		}
+	}
+	return
+}
+
+// Layers returns the list of Forjfile models merged, in order.
+func (f *ForjfileTmpl) Layers() []string {
+	if f == nil {
+		return nil
+	}
+	return f.layers
+}
+
+// Objects returns the Forjfile models which define each object instance, sorted by object and name.
+func (f *ForjfileTmpl) Objects() (ret []TmplObject) {
+	if f == nil {
+		return
+	}
+	ret = make([]TmplObject, 0, len(f.objects))
+	for key, layers := range f.objects {
+		parts := strings.SplitN(key, "/", 2)
+		ret = append(ret, TmplObject{Object: parts[0], Name: parts[1], Layers: layers})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Object != ret[j].Object {
+			return ret[i].Object < ret[j].Object
+		}
+		return ret[i].Name < ret[j].Name
+	})
+	return
+}
@@ -0,0 +1,130 @@
+package forjfile
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadTmpls(t *testing.T) {
+	assert := assert.New(t)
+
+	tmpPath, err := ioutil.TempDir("", "forjj-models")
+	if !assert.NoError(err, "Expect temporary directory to be created") {
+		return
+	}
+	defer os.RemoveAll(tmpPath)
+
+	write := func(model, data string) string {
+		dir := path.Join(tmpPath, model)
+		os.MkdirAll(dir, 0755)
+		assert.NoError(ioutil.WriteFile(path.Join(dir, forjfileName), []byte(data), 0644), "Expect model %s to be written", model)
+		return dir
+	}
+
+	baseline := write("baseline", `forj-settings:
+  organization: company
+local-settings:
+  docker-exe-path: /usr/bin/docker
+deployments:
+  production:
+    type: PRO
+    description: Production
+applications:
+  github:
+    type: upstream
+repositories:
+  common:
+    title: Common repo
+`)
+	team := write("team", `repositories:
+  common:
+    title: Team common repo
+  team-repo:
+    title: Team repo
+`)
+	local := write("local", `forj-settings:
+  organization: myorg
+local-settings:
+  flows-repo: /tmp/flows
+`)
+
+	/*************************************/
+	testCase := "when merging Forjfile models"
+
+	f, loaded, err := LoadTmpls([]string{baseline, team, local})
+	if !assert.NoErrorf(err, "Expect no error %s", testCase) || !assert.Truef(loaded, "Expect models to be loaded %s", testCase) {
+		return
+	}
+	assert.Lenf(f.Layers(), 3, "Expect 3 layers %s", testCase)
+	core := &f.yaml.ForjCore
+	assert.Equalf("myorg", core.ForjSettings.Organization, "Expect the last organization to win %s", testCase)
+	if assert.Containsf(core.Repos, "common", "Expect common repo %s", testCase) {
+		assert.Equalf("Team common repo", core.Repos["common"].Title, "Expect the team title to win %s", testCase)
+	}
+	assert.Containsf(core.Repos, "team-repo", "Expect team repo to be added %s", testCase)
+	assert.Containsf(core.Apps, "github", "Expect baseline app to be kept %s", testCase)
+	assert.Lenf(f.yaml.Deployments, 1, "Expect only the baseline deployment %s", testCase)
+	assert.Containsf(f.yaml.Deployments, "production", "Expect the baseline deployment %s", testCase)
+	assert.Equalf("/usr/bin/docker", f.Workspace.DockerBinPath, "Expect baseline local settings %s", testCase)
+	assert.Equalf("/tmp/flows", f.Workspace.Flow_repo_path, "Expect local settings to be merged %s", testCase)
+
+	objects := make(map[string][]string)
+	for _, object := range f.Objects() {
+		objects[object.Object+"/"+object.Name] = object.Layers
+	}
+	assert.Equalf([]string{baseline + "/Forjfile", team + "/Forjfile"}, objects["repo/common"], "Expect common to be reported in both models %s", testCase)
+	assert.Equalf([]string{team + "/Forjfile"}, objects["repo/team-repo"], "Expect team-repo to be reported in team model %s", testCase)
+
+	/*************************************/
+	testCase = "when a model is not found"
+
+	_, _, err = LoadTmpls([]string{path.Join(tmpPath, "unknown")})
+	assert.Errorf(err, "Expect an error %s", testCase)
+}
+
+func TestLoadTmplsURL(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/Forjfile":
+			w.Write([]byte("repositories:\n  myrepo:\n    title: My repo\n"))
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	defer func(timeout time.Duration) { modelClient.Timeout = timeout }(modelClient.Timeout)
+	modelClient.Timeout = 50 * time.Millisecond
+
+	/*************************************/
+	testCase := "when a model is downloaded"
+
+	f, loaded, err := LoadTmpls([]string{server.URL + "/Forjfile"})
+	if assert.NoErrorf(err, "Expect no error %s", testCase) && assert.Truef(loaded, "Expect the model to be loaded %s", testCase) {
+		assert.Containsf(f.yaml.ForjCore.Repos, "myrepo", "Expect the model repository %s", testCase)
+	}
+
+	/*************************************/
+	testCase = "when the model is not found"
+
+	_, _, err = LoadTmpls([]string{server.URL + "/unknown"})
+	if assert.Errorf(err, "Expect an error %s", testCase) {
+		assert.Containsf(err.Error(), "404", "Expect the status to be reported %s", testCase)
+	}
+
+	/*************************************/
+	testCase = "when the model server does not answer"
+
+	_, _, err = LoadTmpls([]string{server.URL + "/slow"})
+	assert.Errorf(err, "Expect a timeout %s", testCase)
+}
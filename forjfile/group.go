@@ -19,7 +19,7 @@ func (g GroupsStruct) mergeFrom(from GroupsStruct) GroupsStruct {
 		if group, found := g[k]; found {
 			group.mergeFrom(groupFrom)
 		} else {
-			g[k] = groupFrom
+			g[k] = groupFrom.copy()
 		}
 	}
 	return g
@@ -171,6 +171,7 @@ func (g *GroupStruct) Set(source, field, value string) {
 func (g *GroupStruct) mergeFrom(from *GroupStruct) {
 	for _, flag := range from.Flags() {
 		if v, found, source := from.Get(flag); found {
+			g.sources = g.sources.Merge(flag, from.sources)
 			g.Set(source, flag, v.GetString())
 		}
 	}
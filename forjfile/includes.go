@@ -0,0 +1,340 @@
+package forjfile
+
+import (
+	"fmt"
+	"forjj/utils"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/forj-oss/forjj-modules/trace"
+	"gopkg.in/yaml.v2"
+)
+
+// forjfileDir is the directory, next to a Forjfile, where all YAML files are included in the Forjfile.
+const forjfileDir = forjfileName + ".d"
+
+// includeReservedKeys are Forjfile keys which can be defined only in the Forjfile itself.
+var includeReservedKeys = []string{"forj-settings", "infra", "local-settings", "includes", "deployments"}
+
+// forjfileIncludes tracks the files included in a Forjfile and the object instances they define.
+type forjfileIncludes struct {
+	files   []string          // Absolute path of included files, in load order.
+	objects map[string]string // File defining an object instance, by object/instance.
+}
+
+// loadIncludes loads files listed in 'includes' and files found in the Forjfile.d directory
+// next to the Forjfile `aPath`. Their objects are added to the Forjfile.
+func (f *Forge) loadIncludes(forge *DeployForgeYaml, aPath string) error {
+	files, err := includedFiles(path.Dir(aPath), forge.Includes)
+	if err != nil {
+		return fmt.Errorf("Unable to include files in '%s'. %s", aPath, err)
+	}
+
+	for _, file := range files {
+		yaml_data := []byte(nil)
+		if _, d, e := loadFile(file); e != nil {
+			return fmt.Errorf("Unable to include '%s' in '%s'. %s", file, aPath, e)
+		} else {
+			yaml_data = d
+		}
+
+		included := new(DeployForgeYaml)
+		if e := loadIncluded(yaml_data, included); e != nil {
+			return fmt.Errorf("Unable to include '%s' in '%s'. %s", file, aPath, e)
+		}
+		included.initDefaults(forge.forge)
+		f.keepDocument(file, yaml_data)
+		included.setFileSources(file, f.docs[file])
+
+		if e := forge.include(file, included); e != nil {
+			return fmt.Errorf("Unable to include '%s' in '%s'. %s", file, aPath, e)
+		}
+		gotrace.Trace("%s included.", file)
+	}
+	return nil
+}
+
+// includedFiles returns the absolute path of files to include. `includes` entries are
+// relative to `dir` and can be glob patterns. Forjfile.d files are included after them.
+func includedFiles(dir string, includes []string) (files []string, err error) {
+	found := make(map[string]bool)
+	add := func(file string) error {
+		absFile, err := utils.Abs(file)
+		if err != nil {
+			return err
+		}
+		if !found[absFile] {
+			found[absFile] = true
+			files = append(files, absFile)
+		}
+		return nil
+	}
+
+	for _, include := range includes {
+		if !path.IsAbs(include) {
+			include = path.Join(dir, include)
+		}
+		if !strings.ContainsAny(include, "*?[") {
+			if err = add(include); err != nil {
+				return
+			}
+			continue
+		}
+		matches, e := filepath.Glob(include)
+		if e != nil {
+			return nil, fmt.Errorf("Invalid include pattern '%s'. %s", include, e)
+		}
+		sort.Strings(matches)
+		for _, match := range matches {
+			if err = add(match); err != nil {
+				return
+			}
+		}
+	}
+
+	entries, e := ioutil.ReadDir(path.Join(dir, forjfileDir))
+	if e != nil { // No Forjfile.d directory.
+		return
+	}
+	for _, entry := range entries {
+		if ext := path.Ext(entry.Name()); !entry.Mode().IsRegular() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		if err = add(path.Join(dir, forjfileDir, entry.Name())); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// loadIncluded reads an included file. It can't define Forjfile settings.
+func loadIncluded(data []byte, included *DeployForgeYaml) error {
+	var keys map[string]interface{}
+	if err := yaml.Unmarshal(data, &keys); err != nil {
+		return err
+	}
+	for _, key := range includeReservedKeys {
+		if _, found := keys[key]; found {
+			return fmt.Errorf("'%s' can be defined only in the Forjfile", key)
+		}
+	}
+	return yaml.Unmarshal(data, included)
+}
+
+// include adds the object instances of an included file.
+// An object instance can be defined only once in the Forjfile and its included files.
+func (f *DeployForgeYaml) include(file string, included *DeployForgeYaml) error {
+	if f.included.objects == nil {
+		f.included.objects = make(map[string]string)
+	}
+	if f.Repos == nil {
+		f.Repos = make(ReposStruct)
+	}
+	if f.Apps == nil {
+		f.Apps = make(AppsStruct)
+	}
+	if f.Users == nil {
+		f.Users = make(UsersStruct)
+	}
+	if f.Groups == nil {
+		f.Groups = make(GroupsStruct)
+	}
+	if f.More == nil {
+		f.More = make(map[string]map[string]ForjValues)
+	}
+
+	for name, repo := range included.Repos {
+		_, defined := f.Repos[name]
+		if err := f.includeInstance(file, "repo", name, defined); err != nil {
+			return err
+		}
+		f.Repos[name] = repo
+	}
+	for name, app := range included.Apps {
+		_, defined := f.Apps[name]
+		if err := f.includeInstance(file, "app", name, defined); err != nil {
+			return err
+		}
+		f.Apps[name] = app
+	}
+	for name, user := range included.Users {
+		_, defined := f.Users[name]
+		if err := f.includeInstance(file, "user", name, defined); err != nil {
+			return err
+		}
+		f.Users[name] = user
+	}
+	for name, group := range included.Groups {
+		_, defined := f.Groups[name]
+		if err := f.includeInstance(file, "group", name, defined); err != nil {
+			return err
+		}
+		f.Groups[name] = group
+	}
+	for object, instances := range included.More {
+		if f.More[object] == nil {
+			f.More[object] = make(map[string]ForjValues)
+		}
+		for name, data := range instances {
+			_, defined := f.More[object][name]
+			if err := f.includeInstance(file, object, name, defined); err != nil {
+				return err
+			}
+			f.More[object][name] = data
+			for key := range data {
+				f.mergeSource(object, name, key, included.sources[object+"/"+name])
+			}
+		}
+	}
+	f.included.files = append(f.included.files, file)
+	return nil
+}
+
+// includeInstance records the file defining an object instance.
+func (f *DeployForgeYaml) includeInstance(file, object, name string, defined bool) error {
+	key := object + "/" + name
+	if defined {
+		from, found := f.included.objects[key]
+		if !found {
+			from = "the Forjfile"
+		}
+		return fmt.Errorf("%s '%s' is already defined in '%s'", object, name, from)
+	}
+	f.included.objects[key] = file
+	return nil
+}
+
+// renameIncluded keeps a renamed object instance in the file which defines it.
+func (f *DeployForgeYaml) renameIncluded(object, name, newName string) {
+	if file, found := f.included.objects[object+"/"+name]; found {
+		delete(f.included.objects, object+"/"+name)
+		f.included.objects[object+"/"+newName] = file
+	}
+}
+
+// isIncluded returns true if the object instance is defined in `file`.
+// With an empty `file`, it returns true if the object instance is defined in the Forjfile itself.
+func (f *DeployForgeYaml) isIncluded(file, object, name string) bool {
+	return f.included.objects[object+"/"+name] == file
+}
+
+// withoutIncluded returns a copy of the Forjfile data without the object instances defined in included files.
+func (f *DeployForgeYaml) withoutIncluded() (ret DeployForgeYaml) {
+	ret = *f
+	if len(f.included.files) == 0 {
+		return
+	}
+	ret.Repos = make(ReposStruct)
+	for name, repo := range f.Repos {
+		if f.isIncluded("", "repo", name) {
+			ret.Repos[name] = repo
+		}
+	}
+	ret.Apps = make(AppsStruct)
+	for name, app := range f.Apps {
+		if f.isIncluded("", "app", name) {
+			ret.Apps[name] = app
+		}
+	}
+	ret.Users = make(UsersStruct)
+	for name, user := range f.Users {
+		if f.isIncluded("", "user", name) {
+			ret.Users[name] = user
+		}
+	}
+	ret.Groups = make(GroupsStruct)
+	for name, group := range f.Groups {
+		if f.isIncluded("", "group", name) {
+			ret.Groups[name] = group
+		}
+	}
+	ret.More = make(map[string]map[string]ForjValues)
+	for object, instances := range f.More {
+		for name, data := range instances {
+			if !f.isIncluded("", object, name) {
+				continue
+			}
+			if ret.More[object] == nil {
+				ret.More[object] = make(map[string]ForjValues)
+			}
+			ret.More[object][name] = data
+		}
+	}
+	return
+}
+
+// includedData returns the object instances to save in an included file.
+func (f *DeployForgeYaml) includedData(file string) (ret yaml.MapSlice) {
+	repos := make(ReposStruct)
+	for name, repo := range f.Repos {
+		if f.isIncluded(file, "repo", name) {
+			repos[name] = repo
+		}
+	}
+	apps := make(AppsStruct)
+	for name, app := range f.Apps {
+		if f.isIncluded(file, "app", name) {
+			apps[name] = app
+		}
+	}
+	users := make(UsersStruct)
+	for name, user := range f.Users {
+		if f.isIncluded(file, "user", name) {
+			users[name] = user
+		}
+	}
+	groups := make(GroupsStruct)
+	for name, group := range f.Groups {
+		if f.isIncluded(file, "group", name) {
+			groups[name] = group
+		}
+	}
+	if len(repos) > 0 {
+		ret = append(ret, yaml.MapItem{Key: "repositories", Value: repos})
+	}
+	if len(apps) > 0 {
+		ret = append(ret, yaml.MapItem{Key: "applications", Value: apps})
+	}
+	if len(users) > 0 {
+		ret = append(ret, yaml.MapItem{Key: "users", Value: users})
+	}
+	if len(groups) > 0 {
+		ret = append(ret, yaml.MapItem{Key: "groups", Value: groups})
+	}
+
+	objects := make([]string, 0, len(f.More))
+	for object := range f.More {
+		objects = append(objects, object)
+	}
+	sort.Strings(objects)
+	for _, object := range objects {
+		instances := make(map[string]ForjValues)
+		for name, data := range f.More[object] {
+			if f.isIncluded(file, object, name) {
+				instances[name] = data
+			}
+		}
+		if len(instances) > 0 {
+			ret = append(ret, yaml.MapItem{Key: object, Value: instances})
+		}
+	}
+	return
+}
+
+// saveIncluded writes back the object instances in the file which defines them.
+func (f *Forge) saveIncluded(forge *DeployForgeYaml) error {
+	for _, file := range forge.included.files {
+		yaml_data, err := yaml.Marshal(forge.includedData(file))
+		if err != nil {
+			return err
+		}
+		if err = f.writeForjfile(file, yaml_data); err != nil {
+			return err
+		}
+		gotrace.Trace("Included file saved: %s", file)
+	}
+	return nil
+}
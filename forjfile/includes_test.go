@@ -0,0 +1,114 @@
+package forjfile
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForgeIncludes(t *testing.T) {
+	assert := assert.New(t)
+
+	infraPath, err := ioutil.TempDir("", "forjj-includes")
+	if !assert.NoError(err, "Expect temporary directory to be created") {
+		return
+	}
+	defer os.RemoveAll(infraPath)
+
+	write := func(file, data string) {
+		file = path.Join(infraPath, file)
+		os.MkdirAll(path.Dir(file), 0755)
+		assert.NoError(ioutil.WriteFile(file, []byte(data), 0644), "Expect file %s to be written", file)
+	}
+	read := func(file string) string {
+		data, _ := ioutil.ReadFile(path.Join(infraPath, file))
+		return string(data)
+	}
+	load := func() (err error) {
+		f := new(Forge)
+		if err = f.SetInfraPath(infraPath, true); err == nil {
+			_, err = f.Load("")
+		}
+		return
+	}
+
+	write("Forjfile", `forj-settings:
+  organization: myorg
+deployments:
+  production:
+    type: PRO
+includes:
+- teams/*.yaml
+repositories:
+  myrepo:
+    title: My repo
+`)
+	write("deployments/production/Forjfile", `repositories:
+  myrepo:
+    title: Production repo
+`)
+	write("teams/a.yaml", `# Team A repositories
+repositories:
+  arepo:
+    title: A repo
+`)
+	write("Forjfile.d/apps.yaml", `applications:
+  github:
+    type: upstream
+`)
+
+	/*************************************/
+	testCase := "when loading a Forjfile with included files"
+
+	f := new(Forge)
+	assert.NoErrorf(f.SetInfraPath(infraPath, true), "Expect infra path to be set %s", testCase)
+	_, err = f.Load("")
+	if !assert.NoErrorf(err, "Expect Forjfile to be loaded %s", testCase) {
+		return
+	}
+	core := f.DeployForjfile()
+	assert.Containsf(core.Repos, "myrepo", "Expect myrepo to be loaded %s", testCase)
+	assert.Containsf(core.Repos, "arepo", "Expect arepo to be included %s", testCase)
+	assert.Containsf(core.Apps, "github", "Expect github to be included from Forjfile.d %s", testCase)
+	if history := core.Repos["arepo"].sources.History(FieldRepoTitle); assert.Lenf(history, 1, "Expect one change %s", testCase) {
+		assert.Truef(strings.HasSuffix(history[0].File, "teams/a.yaml"), "Expect the source file to be the included one %s", testCase)
+	}
+	assert.Containsf(f.Forjfiles_name(), "teams/a.yaml", "Expect included files to be committed %s", testCase)
+
+	/*************************************/
+	testCase = "when saving a Forjfile with included files"
+
+	core.Set("test", "repo", "arepo", FieldRepoTitle, "Team A repo")
+	assert.NoErrorf(f.Save(), "Expect Forjfile to be saved %s", testCase)
+	assert.Equalf(`# Team A repositories
+repositories:
+  arepo:
+    title: Team A repo
+`, read("teams/a.yaml"), "Expect arepo to be saved in its file %s", testCase)
+	assert.NotContainsf(read("Forjfile"), "arepo", "Expect arepo to not be saved in the Forjfile %s", testCase)
+	assert.NotContainsf(read("Forjfile"), "github", "Expect github to not be saved in the Forjfile %s", testCase)
+	assert.Containsf(read("Forjfile"), "myrepo", "Expect myrepo to be saved in the Forjfile %s", testCase)
+
+	/*************************************/
+	testCase = "when an object is defined twice"
+
+	write("Forjfile.d/repos.yml", `repositories:
+  myrepo:
+    title: Again
+`)
+	err = load()
+	assert.Errorf(err, "Expect an error %s", testCase)
+
+	/*************************************/
+	testCase = "when an included file defines forj-settings"
+
+	write("Forjfile.d/repos.yml", `forj-settings:
+  organization: other
+`)
+	err = load()
+	assert.Errorf(err, "Expect an error %s", testCase)
+}
@@ -0,0 +1,36 @@
+package forjfile
+
+// ObjectModel is the model of an object instance (user, group or plugin objects) for text/template.
+type ObjectModel struct {
+	forge  *DeployForgeYaml
+	object string
+	Name   string
+}
+
+// Get returns the value of an instance key. 'name' is the instance name, if not defined.
+func (o ObjectModel) Get(key string) (ret string) {
+	if o.forge != nil {
+		var found bool
+		if ret, found, _ = o.forge.GetString(o.object, o.Name, key); found {
+			return
+		}
+	}
+	if key == "name" {
+		return o.Name
+	}
+	return
+}
+
+// GroupMemberModel is the model of a group member, for text/template.
+type GroupMemberModel struct {
+	Group ObjectModel
+	User  ObjectModel
+}
+
+// Get returns the value of a member user key. 'group' is the group name.
+func (m GroupMemberModel) Get(key string) string {
+	if key == "group" {
+		return m.Group.Name
+	}
+	return m.User.Get(key)
+}
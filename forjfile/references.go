@@ -0,0 +1,80 @@
+package forjfile
+
+import (
+	"sort"
+)
+
+// SetFlowLoader defines the function used by Validate to check that declared flows can be loaded.
+// Without it, flows are not checked.
+func (f *Forge) SetFlowLoader(loader func(flow string) error) {
+	if f == nil {
+		return
+	}
+	f.flowLoader = loader
+}
+
+// validateReferences reports every dangling reference between Forjfile objects.
+//
+// - repo upstream-app must be an application of type upstream.
+// - repo in-relation-with must be declared applications.
+// - repo deploy-repo-of must be a declared deployment.
+// - repo flow and forj-settings/default/flow must be loadable flows.
+// - group members must be declared users.
+func (f *Forge) validateReferences(forge *DeployForgeYaml, report *ValidationReport) {
+	flows := make(map[string]error)
+	checkFlow := func(path, flow string) {
+		if flow == "" || f.flowLoader == nil {
+			return
+		}
+		err, found := flows[flow]
+		if !found {
+			err = f.flowLoader(flow)
+			flows[flow] = err
+		}
+		if err != nil {
+			report.AddError(path, "Flow '%s' cannot be loaded. %s", flow, err)
+		}
+	}
+
+	for _, name := range sortedStrings(forge.GetInstances("repo")) {
+		repo := forge.Repos[name]
+		path := "repo/" + name
+		if v := repo.Upstream; v != "" {
+			if app, found := forge.Apps[v]; !found {
+				report.AddError(path+"/upstream-app", "Application '%s' is not declared", v)
+			} else if app.Type != "upstream" {
+				report.AddError(path+"/upstream-app", "Application '%s' is not an upstream application. Its type is '%s'", v, app.Type)
+			}
+		}
+		relApps := make([]string, 0, len(repo.Apps))
+		for relApp := range repo.Apps {
+			relApps = append(relApps, relApp)
+		}
+		for _, relApp := range sortedStrings(relApps) {
+			if v := repo.Apps[relApp]; forge.Apps[v] == nil {
+				report.AddError(path+"/in-relation-with/"+relApp, "Application '%s' is not declared", v)
+			}
+		}
+		if v := repo.Deployment; v != "" {
+			if _, found := f.yaml.Deployments[v]; !found {
+				report.AddError(path+"/deploy-repo-of", "Deployment '%s' doesn't exist. Check deployments section of your Forjfile", v)
+			}
+		}
+		checkFlow(path+"/flow", repo.Flow.Name)
+	}
+
+	for _, name := range sortedStrings(forge.GetInstances("group")) {
+		for _, member := range forge.Groups[name].Members {
+			if _, found := forge.Users[member]; !found {
+				report.AddError("group/"+name+"/members", "User '%s' is not declared", member)
+			}
+		}
+	}
+
+	checkFlow("settings/default/flow", forge.ForjSettings.Default.getFlow())
+}
+
+func sortedStrings(list []string) []string {
+	sort.Strings(list)
+	return list
+}
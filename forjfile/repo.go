@@ -95,12 +95,29 @@ func (r *RepoStruct) mergeFrom(from *RepoStruct) *RepoStruct {
 	}
 	for _, flag := range from.Flags() {
 		if v, found, source := from.Get(flag); found {
+			r.sources = r.sources.Merge(flag, from.sources)
 			r.Set(source, flag, v.GetString())
 		}
 	}
 	return r
 }
 
+// isDefined returns true if any repository field, except its name, is set.
+func (r *RepoStruct) isDefined() bool {
+	if r == nil {
+		return false
+	}
+	for _, flag := range r.Flags() {
+		if flag == FieldRepoName {
+			continue
+		}
+		if v, _ := r.GetString(flag); v != "" {
+			return true
+		}
+	}
+	return false
+}
+
 type RepoFlow struct {
 	Name    string
 	objects map[string]map[string]string
@@ -435,6 +452,21 @@ func (r *RepoStruct) UpstreamAPIUrl() string {
 	return r.driverOwner.DriverAPIUrl
 }
 
+// renameApp updates references to an application renamed.
+func (r *RepoStruct) renameApp(name, newName string) {
+	if r == nil {
+		return
+	}
+	for relName, appName := range r.Apps {
+		if appName == name {
+			r.Apps[relName] = newName
+		}
+	}
+	if r.Upstream == name {
+		r.Upstream = newName
+	}
+}
+
 func (r *RepoStruct) set_forge(f *ForgeYaml) {
 	if r == nil {
 		return
@@ -16,7 +16,7 @@ func (r ReposStruct) mergeFrom(from ReposStruct) ReposStruct {
 			repo.mergeFrom(repoFrom)
 			continue
 		}
-		r[key] = repoFrom
+		r[key] = repoFrom.copy()
 	}
 	return r
 }
@@ -0,0 +1,204 @@
+package forjfile
+
+import (
+	"reflect"
+	"strings"
+)
+
+// JSONSchemaURL is the JSON Schema draft used by the Forjfile schema.
+const JSONSchemaURL = "http://json-schema.org/draft-07/schema#"
+
+// JSONSchema is a JSON Schema document, or a part of it.
+type JSONSchema struct {
+	Schema      string                 `json:"$schema,omitempty"`
+	Ref         string                 `json:"$ref,omitempty"`
+	Title       string                 `json:"title,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Type        string                 `json:"type,omitempty"`
+	Properties  map[string]*JSONSchema `json:"properties,omitempty"`
+	// nil (any key), false (no other keys) or a *JSONSchema.
+	AdditionalProperties interface{}            `json:"additionalProperties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Default              string                 `json:"default,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	WriteOnly            bool                   `json:"writeOnly,omitempty"`
+	Definitions          map[string]*JSONSchema `json:"definitions,omitempty"`
+}
+
+// Forjfile schema definitions, shared by the master Forjfile and deployment Forjfiles.
+const (
+	SchemaDeployForjfile = "deployment-forjfile"
+)
+
+// schemaDefinitions are the core structs described once in the schema definitions, by object name.
+var schemaDefinitions = map[reflect.Type]struct{ name, description string }{
+	reflect.TypeOf(AppStruct{}):       {"app", "Application instance, managed by a forjj plugin."},
+	reflect.TypeOf(RepoStruct{}):      {"repo", "Repository managed by the upstream application."},
+	reflect.TypeOf(UserStruct{}):      {"user", "Forge user."},
+	reflect.TypeOf(GroupStruct{}):     {"group", "Forge group of users."},
+	reflect.TypeOf(DeployForgeYaml{}): {SchemaDeployForjfile, "Deployment Forjfile. Values of the master Forjfile for a deployment."},
+}
+
+// NewForjfileSchema returns the JSON Schema of a Forjfile, built from the core structs.
+//
+// Keys defined by plugins are not described. See AddObject and AddKey to add them.
+func NewForjfileSchema() (ret *JSONSchema) {
+	defs := make(map[string]*JSONSchema)
+	ret = typeSchema(reflect.TypeOf(ForgeYaml{}), defs)
+	ret.Schema = JSONSchemaURL
+	ret.Title = "Forjfile"
+	ret.Description = "Forjj Software factory definition."
+	ret.Definitions = defs
+	return
+}
+
+// Definition returns a schema definition. Ex: app, repo, user, group
+func (s *JSONSchema) Definition(name string) (ret *JSONSchema, found bool) {
+	ret, found = s.Definitions[name]
+	return
+}
+
+// Copy returns a copy of the schema, which can be updated without updating the original one.
+// Keys schemas are shared.
+func (s *JSONSchema) Copy() (ret *JSONSchema) {
+	ret = new(JSONSchema)
+	*ret = *s
+	if s.Properties != nil {
+		ret.Properties = make(map[string]*JSONSchema)
+		for key, value := range s.Properties {
+			ret.Properties[key] = value
+		}
+	}
+	ret.Required = append([]string(nil), s.Required...)
+	return
+}
+
+// AddObject declares an object defined by a plugin, in the master Forjfile and in deployment Forjfiles.
+// The object is a collection of instances described by `instance`.
+func (s *JSONSchema) AddObject(name string, instance *JSONSchema) {
+	if s.Definitions == nil {
+		s.Definitions = make(map[string]*JSONSchema)
+	}
+	s.Definitions[name] = instance
+	instances := func() *JSONSchema {
+		return &JSONSchema{
+			Type:                 "object",
+			Description:          instance.Description,
+			AdditionalProperties: &JSONSchema{Ref: "#/definitions/" + name},
+		}
+	}
+	s.addProperty(name, instances())
+	if deploy, found := s.Definitions[SchemaDeployForjfile]; found {
+		deploy.addProperty(name, instances())
+	}
+}
+
+// AddKey adds a key to an object schema. A required key is added to the list of required keys.
+func (s *JSONSchema) AddKey(name string, key *JSONSchema, required bool) {
+	s.addProperty(name, key)
+	if !required {
+		return
+	}
+	for _, v := range s.Required {
+		if v == name {
+			return
+		}
+	}
+	s.Required = append(s.Required, name)
+}
+
+func (s *JSONSchema) addProperty(name string, property *JSONSchema) {
+	if s.Properties == nil {
+		s.Properties = make(map[string]*JSONSchema)
+	}
+	s.Properties[name] = property
+}
+
+// schemaOf returns the schema of a type. Core structs are described in definitions and referenced.
+func schemaOf(t reflect.Type, defs map[string]*JSONSchema) *JSONSchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	def, found := schemaDefinitions[t]
+	if !found {
+		return typeSchema(t, defs)
+	}
+	if _, found := defs[def.name]; !found {
+		defs[def.name] = nil // Protect from recursive definitions.
+		defs[def.name] = typeSchema(t, defs)
+		defs[def.name].Description = def.description
+	}
+	return &JSONSchema{Ref: "#/definitions/" + def.name}
+}
+
+// typeSchema describes a type as yaml.v2 decodes it.
+func typeSchema(t reflect.Type, defs map[string]*JSONSchema) (ret *JSONSchema) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	ret = new(JSONSchema)
+	if t == reflect.TypeOf(ForjValue{}) {
+		ret.Type = "string"
+		return
+	}
+	switch t.Kind() {
+	case reflect.String:
+		ret.Type = "string"
+	case reflect.Bool:
+		ret.Type = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		ret.Type = "integer"
+	case reflect.Slice, reflect.Array:
+		ret.Type = "array"
+		ret.Items = schemaOf(t.Elem(), defs)
+	case reflect.Map:
+		ret.Type = "object"
+		ret.AdditionalProperties = schemaOf(t.Elem(), defs)
+	case reflect.Struct:
+		ret.Type = "object"
+		ret.Properties = make(map[string]*JSONSchema)
+		for index := 0; index < t.NumField(); index++ {
+			field := t.Field(index)
+			if field.PkgPath != "" {
+				continue // Not exported.
+			}
+			name, inline := yamlFieldName(field)
+			if name == "-" {
+				continue
+			}
+			if !inline {
+				ret.Properties[name] = schemaOf(field.Type, defs)
+				continue
+			}
+			inlined := typeSchema(field.Type, defs)
+			if inlined.Properties == nil {
+				// Inline map. Ex: More
+				ret.AdditionalProperties = inlined.AdditionalProperties
+				continue
+			}
+			for key, value := range inlined.Properties {
+				ret.Properties[key] = value
+			}
+			if inlined.AdditionalProperties != nil {
+				ret.AdditionalProperties = inlined.AdditionalProperties
+			}
+		}
+	}
+	return
+}
+
+// yamlFieldName returns the yaml key of a struct field, as yaml.v2 does.
+func yamlFieldName(field reflect.StructField) (name string, inline bool) {
+	tag := strings.Split(field.Tag.Get("yaml"), ",")
+	name = tag[0]
+	for _, flag := range tag[1:] {
+		if flag == "inline" {
+			inline = true
+		}
+	}
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+	return
+}
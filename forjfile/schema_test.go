@@ -0,0 +1,56 @@
+package forjfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewForjfileSchema(t *testing.T) {
+	assert := assert.New(t)
+
+	/*************************************/
+	testCase := "when building the Forjfile schema from core structs"
+
+	schema := NewForjfileSchema()
+	assert.Equalf(JSONSchemaURL, schema.Schema, "Expect the JSON schema draft %s", testCase)
+	for _, key := range []string{"forj-settings", "repositories", "applications", "users", "groups", "infra", "deployments", "includes"} {
+		assert.Containsf(schema.Properties, key, "Expect '%s' key %s", key, testCase)
+	}
+	if v, found := schema.Properties["repositories"]; assert.Truef(found, "Expect repositories %s", testCase) {
+		assert.Equalf(&JSONSchema{Ref: "#/definitions/repo"}, v.AdditionalProperties, "Expect repositories to refer to repo %s", testCase)
+	}
+	if repo, found := schema.Definition("repo"); assert.Truef(found, "Expect the repo definition %s", testCase) {
+		for _, key := range CoreKeys("repo") {
+			assert.Containsf(repo.Properties, key, "Expect repo '%s' core key %s", key, testCase)
+		}
+		assert.NotContainsf(repo.Properties, "more", "Expect inline maps to not be a key %s", testCase)
+		assert.Equalf(&JSONSchema{Type: "string"}, repo.AdditionalProperties, "Expect other repo keys to be strings %s", testCase)
+	}
+	if app, found := schema.Definition("app"); assert.Truef(found, "Expect the app definition %s", testCase) {
+		assert.Containsf(app.Properties, "depends-on", "Expect inline struct keys %s", testCase)
+	}
+	settings := schema.Properties["forj-settings"]
+	if assert.NotNilf(settings, "Expect forj-settings %s", testCase) {
+		assert.Containsf(settings.Properties, "organization", "Expect organization %s", testCase)
+		assert.Containsf(settings.Properties, "deployment-types", "Expect deployment types %s", testCase)
+	}
+	if deploy, ok := schema.Properties["deployments"].AdditionalProperties.(*JSONSchema); assert.Truef(ok, "Expect a deployment schema %s", testCase) {
+		assert.Containsf(deploy.Properties, "inherits", "Expect deployment core keys %s", testCase)
+		assert.Equalf(&JSONSchema{Ref: "#/definitions/" + SchemaDeployForjfile}, deploy.Properties["define"], "Expect the deployment Forjfile %s", testCase)
+	}
+
+	/*************************************/
+	testCase = "when a plugin object is added"
+
+	project := &JSONSchema{Type: "object", Description: "Project"}
+	project.AddKey("name", &JSONSchema{Type: "string"}, true)
+	project.AddKey("name", &JSONSchema{Type: "string"}, true)
+	schema.AddObject("projects", project)
+	assert.Equalf([]string{"name"}, project.Required, "Expect required keys to be listed once %s", testCase)
+	assert.Containsf(schema.Properties, "projects", "Expect the object in the master Forjfile %s", testCase)
+	deploy, _ := schema.Definition(SchemaDeployForjfile)
+	if assert.NotNilf(deploy, "Expect the deployment Forjfile definition %s", testCase) {
+		assert.Containsf(deploy.Properties, "projects", "Expect the object in deployment Forjfiles %s", testCase)
+	}
+}
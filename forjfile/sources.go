@@ -0,0 +1,146 @@
+package forjfile
+
+import (
+	"forjj/sources_info"
+)
+
+// SourceForjfile is the source of values loaded from a Forjfile.
+const SourceForjfile = "Forjfile"
+
+// GetSources returns the sources history of an object instance.
+func (f *DeployForgeYaml) GetSources(object, instance string) *sourcesinfo.Sources {
+	if !f.init() {
+		return nil
+	}
+	switch object {
+	case "infra":
+		if f.Infra == nil {
+			return nil
+		}
+		return f.Infra.sources
+	case "user":
+		if user, found := f.Users[instance]; found && user != nil {
+			return user.sources
+		}
+	case "group":
+		if group, found := f.Groups[instance]; found && group != nil {
+			return group.sources
+		}
+	case "app":
+		if app, found := f.Apps[instance]; found && app != nil {
+			return app.sources
+		}
+	case "repo":
+		if repo, found := f.Repos[instance]; found && repo != nil {
+			return repo.sources
+		}
+	case "settings":
+		if instance == settingsDefault {
+			return f.ForjSettings.Default.sources
+		}
+		return f.ForjSettings.sources
+	default:
+		return f.sources[object+"/"+instance]
+	}
+	return nil
+}
+
+// setSource records a plugin object instance key change.
+func (f *DeployForgeYaml) setSource(object, instance, key string, change sourcesinfo.Source) {
+	if f == nil {
+		return
+	}
+	if f.sources == nil {
+		f.sources = make(map[string]*sourcesinfo.Sources)
+	}
+	f.sources[object+"/"+instance] = f.sources[object+"/"+instance].SetFrom(key, change)
+}
+
+// mergeSource adds the history of a plugin object instance key from another Forjfile.
+func (f *DeployForgeYaml) mergeSource(object, instance, key string, from *sourcesinfo.Sources) {
+	if f == nil {
+		return
+	}
+	if f.sources == nil {
+		f.sources = make(map[string]*sourcesinfo.Sources)
+	}
+	f.sources[object+"/"+instance] = f.sources[object+"/"+instance].Merge(key, from)
+}
+
+// setFileSources records the file which defines each value loaded, and its line in the document, if given.
+func (f *DeployForgeYaml) setFileSources(file string, doc *yamlDocument) {
+	if f == nil {
+		return
+	}
+	change := func(value string, keys ...string) sourcesinfo.Source {
+		return sourcesinfo.Source{Source: SourceForjfile, Value: value, File: file, Line: doc.line(keys...)}
+	}
+
+	for name, app := range f.Apps {
+		if app == nil {
+			continue
+		}
+		for _, flag := range app.Flags() {
+			if v, found, _ := app.Get(flag); found {
+				app.sources = app.sources.SetFrom(flag, change(v.GetString(), "applications", name, flag))
+			}
+		}
+	}
+	setRepoSources := func(repo *RepoStruct, keys ...string) {
+		for _, flag := range repo.Flags() {
+			if flag == FieldRepoUpstream { // obsolete
+				continue
+			}
+			if v, found, _ := repo.Get(flag); found {
+				repo.sources = repo.sources.SetFrom(flag, change(v.GetString(), append(keys, flag)...))
+			}
+		}
+	}
+	for name, repo := range f.Repos {
+		if repo != nil {
+			setRepoSources(repo, "repositories", name)
+		}
+	}
+	if f.Infra != nil {
+		if _, found := f.Repos[f.Infra.name]; !found {
+			setRepoSources(f.Infra, "infra")
+		}
+	}
+	for name, user := range f.Users {
+		if user == nil {
+			continue
+		}
+		for _, flag := range user.Flags() {
+			if v, found, _ := user.Get(flag); found {
+				user.sources = user.sources.SetFrom(flag, change(v.GetString(), "users", name, flag))
+			}
+		}
+	}
+	for name, group := range f.Groups {
+		if group == nil {
+			continue
+		}
+		for _, flag := range group.Flags() {
+			if v, found, _ := group.Get(flag); found {
+				group.sources = group.sources.SetFrom(flag, change(v.GetString(), "groups", name, flag))
+			}
+		}
+	}
+	for _, flag := range f.ForjSettings.Flags() {
+		if v, found, _ := f.ForjSettings.Get("", flag); found {
+			f.ForjSettings.sources = f.ForjSettings.sources.SetFrom(flag, change(v.GetString(), "forj-settings", flag))
+		}
+	}
+	for _, flag := range f.ForjSettings.Default.Flags() {
+		if v, found, _ := f.ForjSettings.Default.Get(flag); found {
+			f.ForjSettings.Default.sources = f.ForjSettings.Default.sources.SetFrom(flag, change(v.GetString(), "forj-settings", settingsDefault, flag))
+		}
+	}
+	for object, instances := range f.More {
+		for instance, data := range instances {
+			for key, value := range data {
+				f.setSource(object, instance, key, change(value.Get(), object, instance, key))
+			}
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package forjfile
+
+import (
+	"forjj/utils"
+	"sort"
+	"text/template"
+)
+
+// TemplateFuncs returns forjj template functions (see utils.TemplateFuncs) with lookups in this Forjfile:
+//
+// - `forjfile <object> <instance> <key>`: a Forjfile value.
+// - `instances <object>`: instances of an object, in name order.
+// - `deployment <name>`: a deployment model. Ex: {{ (deployment "production").Get "type" }}
+// - `deployments [type]`: deployment names, in name order. All of them if type is not given.
+// - `deployValue <deployment> <object> <instance> <key>`: a value of a deployment Forjfile.
+//
+// Lookups return empty values if the Forjfile is nil.
+func (f *DeployForgeYaml) TemplateFuncs() template.FuncMap {
+	funcs := utils.TemplateFuncs()
+	funcs["forjfile"] = func(object, instance, key string) (ret string) {
+		if f == nil {
+			return
+		}
+		ret, _, _ = f.GetString(object, instance, key)
+		return
+	}
+	funcs["instances"] = func(object string) (ret []string) {
+		if f == nil {
+			return
+		}
+		ret = f.GetInstances(object)
+		sort.Strings(ret)
+		return
+	}
+	funcs["deployment"] = func(name string) (ret DeploymentModel) {
+		if deploy, found := f.deployments()[name]; found {
+			ret = deploy.Model()
+		}
+		return
+	}
+	funcs["deployments"] = func(deployType ...string) (ret []string) {
+		ret = []string{}
+		for name, deploy := range f.deployments() {
+			if len(deployType) == 0 || deployType[0] == "" || deploy.Type == deployType[0] {
+				ret = append(ret, name)
+			}
+		}
+		sort.Strings(ret)
+		return
+	}
+	funcs["deployValue"] = func(deployName, object, instance, key string) (ret string) {
+		if deploy, found := f.deployments()[deployName]; found && deploy.Details != nil {
+			ret, _, _ = deploy.Details.GetString(object, instance, key)
+		}
+		return
+	}
+	return funcs
+}
+
+// deployments returns deployments of the master Forjfile, with their Forjfile.
+//
+// A merged Forjfile has deployments without Forjfile. They are taken from the master Forjfile it is merged from.
+func (f *DeployForgeYaml) deployments() Deployments {
+	if f == nil || f.forge == nil {
+		return nil
+	}
+	if f.forge.master != nil {
+		return f.forge.master.Deployments
+	}
+	return f.forge.Deployments
+}
@@ -0,0 +1,60 @@
+package forjfile
+
+import (
+	"forjj/utils"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeployForgeYamlTemplateFuncs(t *testing.T) {
+	assert := assert.New(t)
+
+	forge := NewForgeYaml()
+	forge.ForjCore.Init(forge)
+	forge.set_defaults()
+	deploy := new(DeploymentStruct)
+	deploy.name = "production"
+	deploy.Type = ProDeployType
+	deploy.Details = NewDeployForgeYaml()
+	deploy.Details.Init(forge)
+	deploy.Details.Set("test", "repo", "myrepo", FieldRepoTitle, "Production repo")
+	forge.Deployments["production"] = deploy
+	core := &forge.ForjCore
+	core.Set("test", "repo", "myrepo", FieldRepoTitle, "My repo")
+	core.Set("test", "repo", "other", FieldRepoTitle, "Other repo")
+
+	evaluate := func(ffd *DeployForgeYaml, value string) string {
+		v, err := utils.Evaluate(value, template.New("test"), nil, ffd.TemplateFuncs())
+		assert.NoErrorf(err, "Expect '%s' to be evaluated", value)
+		return v
+	}
+
+	/*************************************/
+	testCase := "when looking up the Forjfile"
+
+	assert.Equalf("MY REPO", evaluate(core, `{{ forjfile "repo" "myrepo" "title" | upper }}`), "Expect the repo title %s", testCase)
+	assert.Equalf("myrepo,other", evaluate(core, `{{ instances "repo" | join "," }}`), "Expect repos in name order %s", testCase)
+	assert.Equalf("production", evaluate(core, `{{ deployments "PRO" | join "," }}`), "Expect PRO deployments %s", testCase)
+	assert.Equalf("PRO", evaluate(core, `{{ (deployment "production").Get "type" }}`), "Expect the deployment type %s", testCase)
+	assert.Equalf("Production repo", evaluate(core, `{{ deployValue "production" "repo" "myrepo" "title" }}`), "Expect the deployment value %s", testCase)
+
+	/*************************************/
+	testCase = "when looking up a merged Forjfile"
+
+	f := new(Forge)
+	f.yaml = forge
+	merged, err := f.MergeFromDeployment("production")
+	if assert.NoErrorf(err, "Expect the deployment to be merged %s", testCase) {
+		assert.Equalf("Production repo", evaluate(merged, `{{ forjfile "repo" "myrepo" "title" }}`), "Expect the deployment value %s", testCase)
+		assert.Equalf("Production repo", evaluate(merged, `{{ deployValue "production" "repo" "myrepo" "title" }}`), "Expect the deployment Forjfile value %s", testCase)
+		assert.Equalf("production", evaluate(merged, `{{ deployments | join "," }}`), "Expect deployments %s", testCase)
+	}
+
+	/*************************************/
+	testCase = "when no Forjfile is given"
+
+	assert.Equalf("none", evaluate(nil, `{{ forjfile "repo" "myrepo" "title" | default "none" }}`), "Expect an empty value %s", testCase)
+	assert.Equalf("", evaluate(nil, `{{ deployments | join "," }}`), "Expect no deployments %s", testCase)
+}
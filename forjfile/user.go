@@ -32,6 +32,7 @@ func (u *UserStruct) Flags() (flags []string) {
 func (u *UserStruct) mergeFrom(from *UserStruct) {
 	for _, flag := range from.Flags() {
 		if v, found, source := from.Get(flag); found {
+			u.sources = u.sources.Merge(flag, from.sources)
 			u.Set(source, flag, v.GetString())
 		}
 	}
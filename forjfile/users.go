@@ -13,7 +13,7 @@ func (u UsersStruct) mergeFrom(from UsersStruct) UsersStruct {
 		if user, found := u[k]; found {
 			user.mergeFrom(userFrom)
 		} else {
-			u[k] = userFrom
+			u[k] = userFrom.copy()
 		}
 	}
 	return u
@@ -0,0 +1,122 @@
+package forjfile
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Validation issue levels.
+const (
+	ValidationError   = "error"
+	ValidationWarning = "warning"
+)
+
+// ValidationIssue is an error or a warning found while validating a Forjfile.
+type ValidationIssue struct {
+	Deployment string `json:"deployment,omitempty"` // Deployment validated. Empty for the master Forjfile.
+	Path       string `json:"path,omitempty"`       // object/instance[/key] concerned.
+	Level      string `json:"level"`
+	Message    string `json:"message"`
+}
+
+func (i ValidationIssue) String() (ret string) {
+	if i.Deployment != "" {
+		ret = "[" + i.Deployment + "] "
+	}
+	if i.Path != "" {
+		ret += i.Path + ": "
+	}
+	return ret + i.Message
+}
+
+// ValidationReport collects all issues found while validating Forjfiles, instead of stopping on the first one.
+type ValidationReport struct {
+	deployment string
+	Issues     []ValidationIssue
+}
+
+// NewValidationReport returns an empty report.
+func NewValidationReport() (ret *ValidationReport) {
+	ret = new(ValidationReport)
+	ret.Issues = []ValidationIssue{}
+	return
+}
+
+// SetDeployment defines the deployment of next issues reported.
+func (r *ValidationReport) SetDeployment(deploy string) {
+	r.deployment = deploy
+}
+
+// AddError reports an error on an object path.
+func (r *ValidationReport) AddError(path, format string, args ...interface{}) {
+	r.add(ValidationError, path, format, args...)
+}
+
+// AddWarning reports a warning on an object path.
+func (r *ValidationReport) AddWarning(path, format string, args ...interface{}) {
+	r.add(ValidationWarning, path, format, args...)
+}
+
+func (r *ValidationReport) add(level, path, format string, args ...interface{}) {
+	issue := ValidationIssue{
+		Deployment: r.deployment,
+		Path:       path,
+		Level:      level,
+		Message:    fmt.Sprintf(format, args...),
+	}
+	for _, known := range r.Issues {
+		if known == issue {
+			return
+		}
+	}
+	r.Issues = append(r.Issues, issue)
+}
+
+// Errors returns the list of errors reported.
+func (r *ValidationReport) Errors() []ValidationIssue {
+	return r.filter(ValidationError)
+}
+
+// Warnings returns the list of warnings reported.
+func (r *ValidationReport) Warnings() []ValidationIssue {
+	return r.filter(ValidationWarning)
+}
+
+func (r *ValidationReport) filter(level string) (ret []ValidationIssue) {
+	ret = []ValidationIssue{}
+	for _, issue := range r.Issues {
+		if issue.Level == level {
+			ret = append(ret, issue)
+		}
+	}
+	return
+}
+
+// Err returns nil if no errors were reported. Otherwise, it returns an error with all errors reported.
+func (r *ValidationReport) Err() error {
+	errors := r.Errors()
+	switch len(errors) {
+	case 0:
+		return nil
+	case 1:
+		return fmt.Errorf("%s", errors[0])
+	}
+	messages := make([]string, len(errors))
+	for index, issue := range errors {
+		messages[index] = "- " + issue.String()
+	}
+	return fmt.Errorf("%d errors found:\n%s", len(errors), strings.Join(messages, "\n"))
+}
+
+// coreKeys are the Forjfile keys managed by forjj, by object. Other keys must be defined by a plugin.
+var coreKeys = map[string][]string{
+	"app":   {"type", "driver", "version", "depends-on", "flows"},
+	"repo":  {"deploy-repo-of", "upstream-app", "git-remote", "title", "repo-template", "flow", "in-relation-with"},
+	"user":  {userRole},
+	"group": {"role", groupMembers},
+}
+
+// CoreKeys returns the keys of an object managed by forjj.
+func CoreKeys(object string) []string {
+	return coreKeys[object]
+}
@@ -0,0 +1,195 @@
+package forjfile
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidationReport(t *testing.T) {
+	assert := assert.New(t)
+
+	/*************************************/
+	testCase := "when nothing is reported"
+
+	report := NewValidationReport()
+	assert.NoErrorf(report.Err(), "Expect no error %s", testCase)
+
+	/*************************************/
+	testCase = "when errors and warnings are reported"
+
+	report.AddWarning("repo/myrepo", "a warning")
+	assert.NoErrorf(report.Err(), "Expect warnings to not fail %s", testCase)
+	report.AddError("repo/myrepo/title", "first error")
+	report.SetDeployment("staging")
+	report.AddError("app/github", "second error")
+	report.AddError("app/github", "second error")
+	report.SetDeployment("")
+
+	assert.Lenf(report.Errors(), 2, "Expect duplicates to be reported once %s", testCase)
+	assert.Lenf(report.Warnings(), 1, "Expect 1 warning %s", testCase)
+	assert.Equalf("staging", report.Errors()[1].Deployment, "Expect the deployment to be recorded %s", testCase)
+	if err := report.Err(); assert.Errorf(err, "Expect an error %s", testCase) {
+		assert.Containsf(err.Error(), "repo/myrepo/title: first error", "Expect the first error %s", testCase)
+		assert.Containsf(err.Error(), "[staging] app/github: second error", "Expect the second error %s", testCase)
+	}
+}
+
+func TestForgeValidateTo(t *testing.T) {
+	assert := assert.New(t)
+
+	f := new(Forge)
+	f.yaml = NewForgeYaml()
+	f.yaml.ForjCore.Init(f.yaml)
+	f.yaml.set_defaults()
+	newDeploy := func(name, deployType, inherits string) {
+		deploy := new(DeploymentStruct)
+		deploy.name = name
+		deploy.Type = deployType
+		deploy.Inherits = inherits
+		deploy.Details = NewDeployForgeYaml()
+		deploy.Details.Init(f.yaml)
+		f.yaml.Deployments[name] = deploy
+	}
+	newDeploy("other", "", "")
+	newDeploy("loop", DevDeployType, "loop")
+	f.yaml.ForjCore.Set("test", "repo", "myrepo", FieldRepoTitle, "My repo")
+	f.yaml.ForjCore.Repos["myrepo"].Deployment = "unknown"
+
+	/*************************************/
+	testCase := "when the Forjfile has several errors"
+
+	report := NewValidationReport()
+	f.ValidateTo(report)
+	paths := make(map[string]bool)
+	for _, issue := range report.Errors() {
+		paths[issue.Path] = true
+	}
+	assert.Truef(paths["repo/myrepo/deploy-repo-of"], "Expect the repo deployment error %s", testCase)
+	assert.Truef(paths["deployments/other"], "Expect the missing type error %s", testCase)
+	assert.Truef(paths["deployments/loop"], "Expect the inheritance cycle error %s", testCase)
+
+	/*************************************/
+	testCase = "when validating a deployment"
+
+	report = NewValidationReport()
+	assert.Nilf(f.ValidateDeployment("loop", report), "Expect no merged Forjfile %s", testCase)
+	if assert.Lenf(report.Errors(), 1, "Expect 1 error %s", testCase) {
+		assert.Equalf("loop", report.Errors()[0].Deployment, "Expect the deployment to be reported %s", testCase)
+	}
+	report = NewValidationReport()
+	assert.NotNilf(f.ValidateDeployment("production", report), "Expect the merged Forjfile %s", testCase)
+	assert.Lenf(report.Errors(), 1, "Expect the master Forjfile error %s", testCase)
+}
+
+func TestForgeValidateReferences(t *testing.T) {
+	assert := assert.New(t)
+
+	f := new(Forge)
+	f.yaml = NewForgeYaml()
+	f.yaml.ForjCore.Init(f.yaml)
+	f.yaml.set_defaults()
+	deploy := new(DeploymentStruct)
+	deploy.name = "production"
+	deploy.Type = ProDeployType
+	f.yaml.Deployments["production"] = deploy
+	core := &f.yaml.ForjCore
+	core.Set("test", "app", "github", "type", "upstream")
+	core.Set("test", "app", "jenkins", "type", "ci")
+	core.Set("test", "user", "alice", userRole, "admin")
+	core.Set("test", "repo", "myrepo", FieldRepoTitle, "My repo")
+	core.Set("test", "group", "ops", "role", "admin")
+	repo := core.Repos["myrepo"]
+	repo.Upstream = "jenkins"
+	repo.Apps = map[string]string{"ci": "jenkins", "tracker": "jira"}
+	repo.Deployment = "prod"
+	repo.Flow.Name = "unknown-flow"
+	core.Groups["ops"].Members = []string{"alice", "bob"}
+
+	/*************************************/
+	testCase := "when the Forjfile has dangling references"
+
+	report := NewValidationReport()
+	f.ValidateTo(report)
+	paths := make(map[string]string)
+	for _, issue := range report.Errors() {
+		paths[issue.Path] = issue.Message
+	}
+	assert.Containsf(paths["repo/myrepo/upstream-app"], "not an upstream application", "Expect the upstream app error %s", testCase)
+	assert.Containsf(paths["repo/myrepo/in-relation-with/tracker"], "'jira'", "Expect the unknown app error %s", testCase)
+	assert.NotContainsf(paths, "repo/myrepo/in-relation-with/ci", "Expect declared apps to be accepted %s", testCase)
+	assert.Containsf(paths["repo/myrepo/deploy-repo-of"], "'prod'", "Expect the deployment error %s", testCase)
+	assert.Containsf(paths["group/ops/members"], "'bob'", "Expect the unknown member error %s", testCase)
+	assert.NotContainsf(paths, "repo/myrepo/flow", "Expect flows to not be checked without loader %s", testCase)
+
+	/*************************************/
+	testCase = "when a flow loader is set"
+
+	loaded := 0
+	f.SetFlowLoader(func(flow string) error {
+		loaded++
+		if flow == "unknown-flow" {
+			return fmt.Errorf("Unable to find '%s'", flow)
+		}
+		return nil
+	})
+	core.ForjSettings.Default.Set("test", "flow", "unknown-flow")
+	report = NewValidationReport()
+	f.ValidateTo(report)
+	paths = make(map[string]string)
+	for _, issue := range report.Errors() {
+		paths[issue.Path] = issue.Message
+	}
+	assert.Containsf(paths, "repo/myrepo/flow", "Expect the repo flow error %s", testCase)
+	assert.Containsf(paths, "settings/default/flow", "Expect the default flow error %s", testCase)
+	assert.Equalf(1, loaded, "Expect each flow to be loaded once %s", testCase)
+}
+
+func TestForgeValidateDeploymentsIsolation(t *testing.T) {
+	assert := assert.New(t)
+
+	f := new(Forge)
+	f.yaml = NewForgeYaml()
+	f.yaml.ForjCore.Init(f.yaml)
+	f.yaml.set_defaults()
+	newDeploy := func(name string) (deploy *DeploymentStruct) {
+		deploy = new(DeploymentStruct)
+		deploy.name = name
+		deploy.Type = DevDeployType
+		deploy.Details = NewDeployForgeYaml()
+		deploy.Details.Init(f.yaml)
+		f.yaml.Deployments[name] = deploy
+		return
+	}
+	a := newDeploy("a")
+	newDeploy("b")
+	core := &f.yaml.ForjCore
+	core.Set("test", "app", "github", "type", "upstream")
+	core.Set("test", "repo", "myrepo", FieldRepoTitle, "Master title")
+	a.Details.Set("test", "repo", "myrepo", FieldRepoTitle, "A title")
+	a.Details.Set("test", "repo", "arepo", FieldRepoTitle, "A repo")
+	a.Details.Repos["arepo"].Apps = map[string]string{"tracker": "jira"}
+
+	/*************************************/
+	testCase := "when deployments are validated one after the other"
+
+	report := NewValidationReport()
+	ffdA := f.ValidateDeployment("a", report)
+	ffdB := f.ValidateDeployment("b", report)
+	if assert.NotNilf(ffdA, "Expect the 'a' merged Forjfile %s", testCase) && assert.NotNilf(ffdB, "Expect the 'b' merged Forjfile %s", testCase) {
+		v, _, _ := ffdA.GetString("repo", "myrepo", FieldRepoTitle)
+		assert.Equalf("A title", v, "Expect the 'a' value %s", testCase)
+		v, _, _ = ffdB.GetString("repo", "myrepo", FieldRepoTitle)
+		assert.Equalf("Master title", v, "Expect 'b' to not get 'a' values %s", testCase)
+	}
+	v, _, _ := core.GetString("repo", "myrepo", FieldRepoTitle)
+	assert.Equalf("Master title", v, "Expect the master Forjfile to be unchanged %s", testCase)
+	deploys := make(map[string]bool)
+	for _, issue := range report.Errors() {
+		if issue.Path == "repo/arepo/in-relation-with/tracker" {
+			deploys[issue.Deployment] = true
+		}
+	}
+	assert.Equalf(map[string]bool{"a": true}, deploys, "Expect the 'a' error to be reported on 'a' only %s", testCase)
+}
@@ -78,6 +78,31 @@ type WorkspaceStruct struct {
 	More                   map[string]string `yaml:",inline"`
 }
 
+// mergeFrom sets the local settings defined in `from`.
+func (w *WorkspaceStruct) mergeFrom(from WorkspaceStruct) {
+	if from.DockerBinPath != "" {
+		w.DockerBinPath = from.DockerBinPath
+	}
+	if from.Contrib_repo_path != "" {
+		w.Contrib_repo_path = from.Contrib_repo_path
+	}
+	if from.Flow_repo_path != "" {
+		w.Flow_repo_path = from.Flow_repo_path
+	}
+	if from.Repotemplate_repo_path != "" {
+		w.Repotemplate_repo_path = from.Repotemplate_repo_path
+	}
+	if from.SocketDirName != "" {
+		w.SocketDirName = from.SocketDirName
+	}
+	for key, value := range from.More {
+		if w.More == nil {
+			w.More = make(map[string]string)
+		}
+		w.More[key] = value
+	}
+}
+
 // getString return the data of the requested field.
 func (w *WorkspaceData) getString(field string) (value string) {
 	switch field {
@@ -0,0 +1,383 @@
+package forjfile
+
+import (
+	"bytes"
+	"fmt"
+	"forjj/utils"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlDocument is a Forjfile as loaded from the disk.
+//
+// When forjj saves a Forjfile, the new Forjfile data is applied to this document:
+// - unchanged entries are written back as they were, with their comments and anchors.
+// - removed entries are removed, and new ones added at the end of their section. Empty sections are not added.
+// - updated entries are the only one re-encoded. Entries with an alias to an updated anchor are re-encoded too.
+// If nothing has changed, the file is written back byte-identical.
+type yamlDocument struct {
+	data    []byte
+	lines   []string
+	root    *yaml.Node      // Root mapping node
+	indent  int             // Indentation detected in the document.
+	anchors map[string]bool // Anchors written back with the same value, while updating the document.
+}
+
+// newYamlDocument parse a Forjfile data. It returns nil if the document is not a YAML mapping.
+func newYamlDocument(data []byte) (d *yamlDocument) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) != 1 || doc.Content[0].Kind != yaml.MappingNode {
+		return
+	}
+	d = new(yamlDocument)
+	d.data = data
+	d.lines = strings.SplitAfter(string(data), "\n")
+	d.root = doc.Content[0]
+	d.indent = detectIndent(d.root)
+	return
+}
+
+// line returns the line of the entry found at the keys path. See utils.YamlLine
+func (d *yamlDocument) line(keys ...string) int {
+	if d == nil {
+		return 0
+	}
+	return utils.YamlLine(d.root, keys...)
+}
+
+// update returns the document updated with new Forjfile data.
+func (d *yamlDocument) update(data []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("Unable to read the new Forjfile data. %s", err)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) != 1 || doc.Content[0].Kind != yaml.MappingNode {
+		return data, nil
+	}
+	newRoot := doc.Content[0]
+
+	if equalNodes(d.root, newRoot) {
+		return d.data, nil
+	}
+
+	d.anchors = make(map[string]bool)
+	lines, err := d.spliceMapping(d.root, newRoot, 0, len(d.lines), true)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(strings.Join(lines, "")), nil
+}
+
+// spliceMapping returns the lines [start, end[ of the mapping node updated with the new mapping.
+// If sections is true, mapping entries are Forjfile sections. (root and forj-settings entries)
+func (d *yamlDocument) spliceMapping(old, updated *yaml.Node, start, end int, sections bool) (lines []string, err error) {
+	if len(old.Content) == 0 {
+		return d.lines[start:end], nil
+	}
+	seen := make(map[string]bool)
+
+	lines = append(lines, d.lines[start:old.Content[0].Line-1]...)
+
+	var lastTail []string
+	var merges []*yaml.Node // Merge keys kept.
+	for i := 0; i < len(old.Content); i += 2 {
+		key, value := old.Content[i], old.Content[i+1]
+		entryStart := key.Line - 1
+		entryEnd := end
+		if i+2 < len(old.Content) {
+			entryEnd = old.Content[i+2].Line - 1
+		}
+		bodyEnd := d.bodyEnd(value, entryStart, entryEnd)
+		tail := d.lines[bodyEnd:entryEnd]
+		if i+2 >= len(old.Content) {
+			// Keep the last tail to add new entries before it.
+			lastTail = tail
+			tail = nil
+		}
+
+		if key.Value == "<<" { // Merge keys are kept as is, if their aliases are.
+			if d.aliasesKept(value) {
+				d.keepAnchors(value)
+				merges = append(merges, key, value)
+				lines = append(lines, d.lines[entryStart:bodyEnd]...)
+			}
+			lines = append(lines, tail...)
+			continue
+		}
+
+		seen[key.Value] = true
+		newValue := mappingValue(updated, key.Value)
+
+		switch {
+		case newValue == nil: // Entry removed.
+		case equalNodes(value, newValue) && d.aliasesKept(value):
+			lines = append(lines, d.lines[entryStart:bodyEnd]...)
+			d.keepAnchors(value)
+		case value.Kind == yaml.MappingNode && newValue.Kind == yaml.MappingNode && len(newValue.Content) > 0 &&
+			value.Style&yaml.FlowStyle == 0 && len(value.Content) > 0 && value.Line > key.Line:
+			lines = append(lines, d.lines[entryStart:value.Content[0].Line-1]...)
+			var sub []string
+			if sub, err = d.spliceMapping(value, newValue, value.Content[0].Line-1, bodyEnd, sections && key.Value == "forj-settings"); err != nil {
+				return
+			}
+			lines = append(lines, sub...)
+			if value.Anchor != "" {
+				d.anchors[value.Anchor] = equalNodes(value, newValue)
+			}
+		default:
+			var entry []string
+			if entry, err = d.encodeEntry(key, value, newValue, key.Column-1); err != nil {
+				return
+			}
+			lines = append(lines, entry...)
+			if value.Kind != yaml.AliasNode && value.Anchor != "" {
+				d.anchors[value.Anchor] = equalNodes(value, newValue)
+			}
+		}
+		lines = append(lines, tail...)
+	}
+
+	// New entries
+	merged := mergedValues(&yaml.Node{Kind: yaml.MappingNode, Content: merges})
+	indent := old.Content[0].Column - 1
+	for i := 0; i+1 < len(updated.Content); i += 2 {
+		key, value := updated.Content[i], updated.Content[i+1]
+		if seen[key.Value] || sections && isEmptySection(key.Value, value) {
+			continue
+		}
+		if v, found := merged[key.Value]; found && equalNodes(v, value) {
+			continue
+		}
+		var entry []string
+		if entry, err = d.encodeEntry(key, nil, value, indent); err != nil {
+			return
+		}
+		lines = append(lines, entry...)
+	}
+	lines = append(lines, lastTail...)
+	return
+}
+
+// bodyEnd returns the end of the entry, without the comments and blank lines which follow it.
+// Those lines are kept, as they usually describe the next entry.
+func (d *yamlDocument) bodyEnd(value *yaml.Node, start, end int) int {
+	if value.Kind == yaml.ScalarNode && value.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		return end
+	}
+	for end > start+1 {
+		line := strings.TrimSpace(d.lines[end-1])
+		if line != "" && !strings.HasPrefix(line, "#") {
+			break
+		}
+		end--
+	}
+	return end
+}
+
+// encodeEntry encodes a key/value entry at the given indentation.
+// If the old value is a scalar, its style, comments and anchor are kept.
+func (d *yamlDocument) encodeEntry(key, old, value *yaml.Node, indent int) (lines []string, err error) {
+	newKey := *key
+	newKey.HeadComment = ""
+	newKey.FootComment = ""
+
+	newValue := value
+	if old != nil && old.Kind == yaml.ScalarNode && value.Kind == yaml.ScalarNode {
+		scalar := *old
+		scalar.Value = value.Value
+		scalar.Tag = value.Tag
+		if old.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 && !strings.Contains(value.Value, "\n") {
+			scalar.Style = value.Style
+		}
+		newValue = &scalar
+	} else if old != nil && old.Kind != yaml.AliasNode && old.Anchor != "" {
+		anchored := *value
+		anchored.Anchor = old.Anchor
+		newValue = &anchored
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(d.indent)
+	if err = enc.Encode(&yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{&newKey, newValue}}); err != nil {
+		return nil, fmt.Errorf("Unable to encode '%s'. %s", key.Value, err)
+	}
+	if err = enc.Close(); err != nil {
+		return nil, fmt.Errorf("Unable to encode '%s'. %s", key.Value, err)
+	}
+
+	prefix := strings.Repeat(" ", indent)
+	for _, line := range strings.SplitAfter(buf.String(), "\n") {
+		if line == "" {
+			continue
+		}
+		lines = append(lines, prefix+line)
+	}
+	return
+}
+
+// keepAnchors records anchors of a node written back as is.
+func (d *yamlDocument) keepAnchors(node *yaml.Node) {
+	if node == nil || node.Kind == yaml.AliasNode {
+		return
+	}
+	if node.Anchor != "" {
+		d.anchors[node.Anchor] = true
+	}
+	for _, child := range node.Content {
+		d.keepAnchors(child)
+	}
+}
+
+// aliasesKept returns true if all aliases of the node refer to anchors written back with the same value.
+// Otherwise, the node must be re-encoded, without aliases.
+func (d *yamlDocument) aliasesKept(node *yaml.Node) bool {
+	if node == nil {
+		return true
+	}
+	if node.Kind == yaml.AliasNode {
+		return d.anchors[node.Value]
+	}
+	for _, child := range node.Content {
+		if !d.aliasesKept(child) {
+			return false
+		}
+	}
+	return true
+}
+
+// mappingValue returns the value of a mapping key, or nil.
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// mergedValues returns the mapping values, including values merged with '<<'.
+func mergedValues(mapping *yaml.Node) (values map[string]*yaml.Node) {
+	values = make(map[string]*yaml.Node)
+	mapping = resolveAlias(mapping)
+	if mapping.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != "<<" {
+			continue
+		}
+		merge := resolveAlias(mapping.Content[i+1])
+		sources := []*yaml.Node{merge}
+		if merge.Kind == yaml.SequenceNode {
+			sources = merge.Content
+		}
+		for _, source := range sources {
+			for key, value := range mergedValues(source) {
+				if _, found := values[key]; !found {
+					values[key] = value
+				}
+			}
+		}
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if key := mapping.Content[i].Value; key != "<<" {
+			values[key] = mapping.Content[i+1]
+		}
+	}
+	return
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node != nil && node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
+
+// isEmptyNode returns true if the node is null, or an empty mapping or sequence.
+func isEmptyNode(node *yaml.Node) bool {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return node.ShortTag() == "!!null"
+	case yaml.MappingNode, yaml.SequenceNode:
+		return len(node.Content) == 0
+	}
+	return false
+}
+
+// isEmptySection returns true if a Forjfile section has no data: it is empty or an empty string.
+// forj-settings is empty if all its sections are.
+func isEmptySection(key string, node *yaml.Node) bool {
+	if isEmptyNode(node) || node.Kind == yaml.ScalarNode && node.Value == "" {
+		return true
+	}
+	if key != "forj-settings" || node.Kind != yaml.MappingNode {
+		return false
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if !isEmptySection(node.Content[i].Value, node.Content[i+1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// equalNodes returns true if both nodes represent the same data. Scalars are compared as strings.
+// null and empty mappings or sequences are equal.
+func equalNodes(a, b *yaml.Node) bool {
+	a, b = resolveAlias(a), resolveAlias(b)
+	if a == nil || b == nil {
+		return a == b
+	}
+	if isEmptyNode(a) || isEmptyNode(b) {
+		return isEmptyNode(a) && isEmptyNode(b)
+	}
+	if a.Kind != b.Kind {
+		return false
+	}
+	switch a.Kind {
+	case yaml.ScalarNode:
+		return a.Value == b.Value
+	case yaml.SequenceNode:
+		if len(a.Content) != len(b.Content) {
+			return false
+		}
+		for i := range a.Content {
+			if !equalNodes(a.Content[i], b.Content[i]) {
+				return false
+			}
+		}
+		return true
+	case yaml.MappingNode:
+		aValues, bValues := mergedValues(a), mergedValues(b)
+		if len(aValues) != len(bValues) {
+			return false
+		}
+		for key, value := range aValues {
+			if !equalNodes(value, bValues[key]) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// detectIndent returns the indentation used by the first nested mapping. 2 by default.
+func detectIndent(node *yaml.Node) int {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if value.Kind != yaml.MappingNode || len(value.Content) == 0 || value.Style&yaml.FlowStyle != 0 {
+			continue
+		}
+		if indent := value.Content[0].Column - key.Column; indent > 0 {
+			return indent
+		}
+	}
+	return 2
+}
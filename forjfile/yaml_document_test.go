@@ -0,0 +1,249 @@
+package forjfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testYamlDocument = `# My forge
+forj-settings:
+  organization: myorg   # The organization
+
+# Our repositories
+repositories:
+  myrepo:
+    title: "My repo"
+    flow: default # The default flow
+  other:
+    <<: &common
+      flow: github-pr
+    title: Other
+
+applications:
+  github:
+    type: upstream
+# End of file
+`
+
+func TestYamlDocumentUpdate(t *testing.T) {
+	assert := assert.New(t)
+
+	doc := newYamlDocument([]byte(testYamlDocument))
+	if !assert.NotNil(doc, "Expect the document to be loaded") {
+		return
+	}
+
+	/*************************************/
+	testCase := "when nothing has changed"
+
+	data, err := doc.update([]byte(`
+applications:
+  github:
+    type: upstream
+forj-settings:
+  organization: myorg
+repositories:
+  myrepo:
+    flow: default
+    title: My repo
+  other:
+    flow: github-pr
+    title: Other
+`))
+	assert.NoErrorf(err, "Expect no error %s", testCase)
+	assert.Equalf(testYamlDocument, string(data), "Expect the document to be byte-identical %s", testCase)
+
+	/*************************************/
+	testCase = "when a value is updated"
+
+	data, err = doc.update([]byte(`
+applications:
+  github:
+    type: upstream
+forj-settings:
+  organization: myorg
+repositories:
+  myrepo:
+    flow: github-pr
+    title: My repo
+  other:
+    flow: github-pr
+    title: Other
+`))
+	assert.NoErrorf(err, "Expect no error %s", testCase)
+	assert.Equalf(`# My forge
+forj-settings:
+  organization: myorg   # The organization
+
+# Our repositories
+repositories:
+  myrepo:
+    title: "My repo"
+    flow: github-pr # The default flow
+  other:
+    <<: &common
+      flow: github-pr
+    title: Other
+
+applications:
+  github:
+    type: upstream
+# End of file
+`, string(data), "Expect only the flow to be updated %s", testCase)
+
+	/*************************************/
+	testCase = "when entries are added and removed"
+
+	data, err = doc.update([]byte(`
+applications:
+  github:
+    type: upstream
+  jenkins:
+    type: ci
+forj-settings:
+  organization: myorg
+repositories:
+  other:
+    flow: github-pr
+    title: Other
+`))
+	assert.NoErrorf(err, "Expect no error %s", testCase)
+	assert.Equalf(`# My forge
+forj-settings:
+  organization: myorg   # The organization
+
+# Our repositories
+repositories:
+  other:
+    <<: &common
+      flow: github-pr
+    title: Other
+
+applications:
+  github:
+    type: upstream
+  jenkins:
+    type: ci
+# End of file
+`, string(data), "Expect myrepo removed and jenkins added %s", testCase)
+}
+
+func TestYamlDocumentUpdateData(t *testing.T) {
+	assert := assert.New(t)
+
+	/*************************************/
+	testCase := "when an anchored entry is updated"
+
+	doc := newYamlDocument([]byte(`repositories:
+  myrepo: &repo
+    flow: default
+  other: *repo
+applications:
+  github: &app
+    type: upstream
+  jenkins:
+    <<: *app
+    driver: jenkins
+`))
+	data, err := doc.update([]byte(`
+applications:
+  github:
+    type: ci
+  jenkins:
+    driver: jenkins
+    type: upstream
+repositories:
+  myrepo:
+    flow: github-pr
+  other:
+    flow: default
+`))
+	assert.NoErrorf(err, "Expect no error %s", testCase)
+	assert.Equalf(`repositories:
+  myrepo: &repo
+    flow: github-pr
+  other:
+    flow: default
+applications:
+  github: &app
+    type: ci
+  jenkins:
+    driver: jenkins
+    type: upstream
+`, string(data), "Expect aliases to the updated anchors to be expanded %s", testCase)
+
+	/*************************************/
+	testCase = "when a nested mapping is emptied"
+
+	doc = newYamlDocument([]byte(`repositories:
+  myrepo:
+    title: My repo
+`))
+	data, err = doc.update([]byte(`
+repositories:
+  myrepo: {}
+`))
+	assert.NoErrorf(err, "Expect no error %s", testCase)
+	assert.Equalf(`repositories:
+  myrepo: {}
+`, string(data), "Expect an empty mapping %s", testCase)
+
+	/*************************************/
+	testCase = "when empty sections are not in the document"
+
+	doc = newYamlDocument([]byte(testYamlDocument))
+	data, err = doc.update([]byte(`
+applications:
+  github:
+    type: upstream
+forj-settings:
+  default: {}
+  organization: myorg
+groups: {}
+infra: {}
+repositories:
+  myrepo:
+    flow: default
+    title: My repo
+  other:
+    flow: github-pr
+    title: Other
+users: {}
+`))
+	assert.NoErrorf(err, "Expect no error %s", testCase)
+	assert.Equalf(testYamlDocument, string(data), "Expect empty sections to not be added %s", testCase)
+
+	/*************************************/
+	testCase = "when an empty instance is added"
+
+	doc = newYamlDocument([]byte(`repositories:
+  myrepo:
+    title: My repo
+users:
+`))
+	data, err = doc.update([]byte(`
+repositories:
+  myrepo:
+    title: My repo
+  other: {}
+users: {}
+`))
+	assert.NoErrorf(err, "Expect no error %s", testCase)
+	assert.Equalf(`repositories:
+  myrepo:
+    title: My repo
+  other: {}
+users:
+`, string(data), "Expect the empty instance to be added %s", testCase)
+}
+
+func TestYamlDocumentInvalid(t *testing.T) {
+	assert := assert.New(t)
+
+	/*************************************/
+	testCase := "when the document is not a mapping"
+
+	assert.Nilf(newYamlDocument([]byte("- a\n- b\n")), "Expect no document %s", testCase)
+	assert.Nilf(newYamlDocument([]byte("")), "Expect no document %s", testCase)
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"forjj/utils"
+
 	"github.com/forj-oss/forjj-modules/trace"
 )
 
@@ -55,7 +57,7 @@ func (a *Forj) SetDefault(action string) {
 
 // LoadForge loads the forjj options definitions from the LoadContext().
 func (a *Forj) LoadForge() (err error) {
-	if v := a.forjfile_tmpl_path; v != "" && a.w.InfraPath() == v {
+	if utils.InStringList(a.w.InfraPath(), a.forjfile_tmpl_paths...) != "" {
 		gotrace.Info("If your Forfile template has defined local settings and/or credentials data, those data will " +
 			"be moved to the internal forjj workspace.")
 		return
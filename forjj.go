@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"github.com/alecthomas/kingpin"
 	"github.com/forj-oss/forjj-modules/trace"
 	"log"
@@ -41,16 +42,25 @@ func main() {
 		}
 	}*/
 	if err == nil && forj_app.w.Error() != nil {
-		kingpin.Fatalf("Unable to go on. %s", forj_app.w.Error())
+		err = fmt.Errorf("Unable to go on. %s", forj_app.w.Error())
+		if !forj_app.output.isJSON() {
+			kingpin.Fatalf("%s", err)
+		}
+	}
+	if err != nil && forj_app.output.isJSON() {
+		forj_app.output.done(err)
 	}
 
 	//	TODO : Use cli : Re-apply following function
 	// forj_app.InitializeDriversAPI()
-	defer forj_app.driver_cleanup_all()
 	action := kingpin.MustParse(parse, err)
 	if f, found := forj_app.actionDispatch[forj_app.contextAction] ; found {
-		f(action)
+		err = f(action)
 	}
+	forj_app.driver_cleanup_all()
+
+	// Top level error handler.
+	forj_app.output.done(err)
 }
 
 func (a *Forj) contextDisplayed() {
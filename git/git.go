@@ -67,7 +67,8 @@ func ShowGitPath() (msg string) {
 }
 
 // GetStatus return an GitStatus struct with the list of files, added, updated and
-func GetStatus() (gs *Status) {
+// The status can be limited to some paths.
+func GetStatus(paths ...string) (gs *Status) {
 	gs = new(Status)
 
 	gs.Ready = make(map[string][]string)
@@ -80,7 +81,7 @@ func GetStatus() (gs *Status) {
 
 	var s string
 
-	s, gs.Err = Get("status", "--porcelain")
+	s, gs.Err = Get(append([]string{"status", "--porcelain", "--"}, paths...)...)
 	if gs.Err != nil || s == "" {
 		return
 	}
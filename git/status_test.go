@@ -1,6 +1,10 @@
 package git
 
 import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
 	"testing"
 )
 
@@ -67,3 +71,46 @@ func TestInit(t *testing.T) {
 		t.Errorf("Expected gitFiles to contains the 'D' element. Not found.")
 	}
 }
+
+func TestGetStatusPaths(t *testing.T) {
+	t.Log("Expecting GetStatus to be limited to the paths given.")
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available.")
+	}
+
+	repoPath, err := ioutil.TempDir("", "forjj-git-")
+	if err != nil {
+		t.Fatalf("Unable to create a temporary directory. %s", err)
+	}
+	defer os.RemoveAll(repoPath)
+
+	if out, err := exec.Command("git", "init", "-q", repoPath).CombinedOutput(); err != nil {
+		t.Fatalf("git init failed. %s: %s", err, out)
+	}
+	for _, dir := range []string{"a", "b"} {
+		os.Mkdir(path.Join(repoPath, dir), 0755)
+		if err := ioutil.WriteFile(path.Join(repoPath, dir, "file"), []byte(dir), 0644); err != nil {
+			t.Fatalf("Unable to write '%s/file'. %s", dir, err)
+		}
+	}
+	if out, err := exec.Command("git", "-C", repoPath, "add", "a", "b").CombinedOutput(); err != nil {
+		t.Fatalf("git add failed. %s: %s", err, out)
+	}
+
+	// Run the function
+	t.Log("Running GetStatus(\"a\")...")
+	var status *Status
+	RunInPath(repoPath, func() error {
+		status = GetStatus("a")
+		return nil
+	})
+
+	// Test the result
+	if status.Err != nil {
+		t.Fatalf("Expected GetStatus to return no error. Got '%s'.", status.Err)
+	}
+	if v := status.Ready.Files(); len(v) != 1 || v[0] != "a/file" {
+		t.Errorf("Expected GetStatus to report only 'a/file'. Got %s.", v)
+	}
+}
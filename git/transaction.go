@@ -0,0 +1,162 @@
+package git
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/forj-oss/forjj-modules/trace"
+)
+
+const rollbackPrefix = "forjj-rollback-"
+
+// Transaction keeps the starting point of a GIT repository to restore it if a task fails.
+//
+// The starting point is the current commit, the index and the working tree, with files not controlled.
+// Ignored files are not restored.
+type Transaction struct {
+	name     string // Repository description. Ex: infra
+	repoPath string // Absolute path to the repository.
+	head     string // Commit found at start. Empty if the repository had no commit.
+	index    string // Tree of the index found at start.
+	worktree string // Tree of the working tree found at start.
+}
+
+// Begin records the starting point of the repository given.
+func Begin(name, repoPath string) (t *Transaction, err error) {
+	t = new(Transaction)
+	t.name = name
+	t.repoPath = repoPath
+	err = RunInPath(repoPath, func() (err error) {
+		// No error reported if the repository has no commit yet.
+		t.head, _ = Get("rev-parse", "--verify", "-q", "HEAD")
+		t.index, t.worktree, err = snapshot()
+		return
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Unable to start the %s repository transaction. %s", name, err)
+	}
+	gotrace.Trace("GIT: %s repository transaction started at '%s'", name, t.head)
+	return
+}
+
+// Rollback restores the repository to the starting point.
+//
+// Nothing is lost: the work done since the start (commits and uncommitted files) is kept in a branch
+// named `forjj-rollback-<date>`.
+//
+// It returns the list of restore actions done.
+func (t *Transaction) Rollback() (report []string, err error) {
+	if t == nil {
+		return
+	}
+
+	backup := rollbackPrefix + time.Now().Format("20060102-150405")
+
+	err = RunInPath(t.repoPath, func() error {
+		head, _ := Get("rev-parse", "--verify", "-q", "HEAD")
+		index, worktree, err := snapshot()
+		if err != nil {
+			return err
+		}
+		if head == t.head && index == t.index && worktree == t.worktree {
+			return nil
+		}
+
+		// Keep the work done in a backup branch.
+		args := []string{"commit-tree", worktree, "-m", "forjj rollback backup"}
+		if head != "" {
+			args = append(args, "-p", head)
+		}
+		commit, err := Get(args...)
+		if err != nil {
+			return fmt.Errorf("Unable to save the work done. %s", err)
+		}
+		if Do("branch", backup, commit) != 0 {
+			return fmt.Errorf("Unable to create the backup branch '%s'", backup)
+		}
+
+		// Restore the working tree, then the index and the commit.
+		if Do("add", "--all") != 0 {
+			return fmt.Errorf("Unable to add the working tree files")
+		}
+		if Do("read-tree", "-u", "--reset", t.worktree) != 0 {
+			return fmt.Errorf("Unable to restore the working tree")
+		}
+		if Do("read-tree", t.index) != 0 {
+			return fmt.Errorf("Unable to restore the index")
+		}
+		switch {
+		case t.head == head:
+		case t.head == "":
+			if Do("update-ref", "-d", "HEAD") != 0 {
+				return fmt.Errorf("Unable to remove the commits done")
+			}
+		default:
+			if Do("reset", "-q", "--soft", t.head) != 0 {
+				return fmt.Errorf("Unable to reset to '%s'", t.head)
+			}
+		}
+		if t.head == "" {
+			report = append(report, fmt.Sprintf("%s: restored with no commit. Work done kept in branch '%s'.", t.name, backup))
+		} else {
+			report = append(report, fmt.Sprintf("%s: restored to commit '%s'. Work done kept in branch '%s'.", t.name, t.head, backup))
+		}
+		return nil
+	})
+	if err != nil {
+		err = fmt.Errorf("Unable to rollback the %s repository. %s", t.name, err)
+		return
+	}
+	if report == nil {
+		report = append(report, fmt.Sprintf("%s: nothing to restore.", t.name))
+	}
+	return
+}
+
+// snapshot returns the GIT trees of the index and of the working tree of the current repository.
+// The working tree tree includes files not controlled, except ignored files. The index is not updated.
+func snapshot() (index, worktree string, err error) {
+	if index, err = Get("write-tree"); err != nil {
+		return "", "", fmt.Errorf("Unable to get the index tree. %s", err)
+	}
+
+	file, err := ioutil.TempFile("", "forjj-index-")
+	if err != nil {
+		return
+	}
+	file.Close()
+	defer os.Remove(file.Name())
+
+	// Start from the repository index, to keep files controlled even if they are ignored.
+	gitDir, err := Get("rev-parse", "--git-dir")
+	if err != nil {
+		return "", "", fmt.Errorf("Unable to find the repository. %s", err)
+	}
+	if data, e := ioutil.ReadFile(path.Join(gitDir, "index")); e == nil {
+		err = ioutil.WriteFile(file.Name(), data, 0600)
+	} else {
+		err = os.Remove(file.Name())
+	}
+	if err != nil {
+		return
+	}
+
+	withIndex := func(opts ...string) (string, error) {
+		cmd := exec.Command("git", opts...)
+		cmd.Env = append(os.Environ(), "GIT_INDEX_FILE="+file.Name())
+		out, err := cmd.Output()
+		return strings.Trim(string(out), " \n"), err
+	}
+	if _, err = withIndex("add", "--all"); err != nil {
+		return "", "", fmt.Errorf("Unable to get the working tree files. %s", err)
+	}
+	if worktree, err = withIndex("write-tree"); err != nil {
+		return "", "", fmt.Errorf("Unable to get the working tree. %s", err)
+	}
+	return
+}
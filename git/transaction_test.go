@@ -0,0 +1,185 @@
+package git
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"testing"
+)
+
+// newTestRepo creates a GIT repository for transaction tests.
+// It returns the repository path and functions to run git and to write files in it.
+func newTestRepo(t *testing.T) (repoPath string, gitRun func(args ...string) string, writeFile func(name, content string)) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available.")
+	}
+
+	repoPath, err := ioutil.TempDir("", "forjj-git-")
+	if err != nil {
+		t.Fatalf("Unable to create a temporary directory. %s", err)
+	}
+
+	gitRun = func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-C", repoPath}, args...)...)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s failed. %s: %s", strings.Join(args, " "), err, out)
+		}
+		return strings.Trim(string(out), " \n")
+	}
+	writeFile = func(name, content string) {
+		if err := ioutil.WriteFile(path.Join(repoPath, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Unable to write '%s'. %s", name, err)
+		}
+	}
+	gitRun("init", "-q")
+	return
+}
+
+// setTestIdentity defines the GIT identity required to commit, and returns a function to restore it.
+func setTestIdentity() (restore func()) {
+	envs := []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME", "GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"}
+	saved := make([]string, len(envs))
+	for i, env := range envs {
+		saved[i] = os.Getenv(env)
+		os.Setenv(env, "test@forjj")
+	}
+	return func() {
+		for i, env := range envs {
+			os.Setenv(env, saved[i])
+		}
+	}
+}
+
+func TestTransactionRollback(t *testing.T) {
+	t.Log("Expecting Rollback to restore the repository with the user work and keep the partial work.")
+
+	repoPath, gitRun, writeFile := newTestRepo(t)
+	defer os.RemoveAll(repoPath)
+	defer setTestIdentity()()
+
+	writeFile("Forjfile", "initial")
+	gitRun("add", "Forjfile")
+	gitRun("commit", "-q", "-m", "initial")
+	start := gitRun("rev-parse", "HEAD")
+
+	// User work not committed.
+	writeFile("Forjfile", "user update")
+	writeFile("notes", "user notes")
+	writeFile("staged", "user staged")
+	gitRun("add", "staged")
+	startStatus := gitRun("status", "--porcelain")
+
+	// Run the function
+	t.Log("Running Begin(\"infra\", repoPath)...")
+	tr, err := Begin("infra", repoPath)
+
+	// Test the result
+	if err != nil {
+		t.Fatalf("Expected Begin to return no error. Got '%s'.", err)
+	}
+	if tr.head != start {
+		t.Errorf("Expected transaction to start at '%s'. Got '%s'.", start, tr.head)
+	}
+
+	// Simulate a driver which committed, then failed with files added and not controlled.
+	writeFile("Forjfile", "updated")
+	gitRun("commit", "-q", "-a", "-m", "driver commit")
+	writeFile("plugin.yaml", "partial")
+
+	// Run the function
+	t.Log("Running tr.Rollback()...")
+	report, err := tr.Rollback()
+
+	// Test the result
+	if err != nil {
+		t.Fatalf("Expected Rollback to return no error. Got '%s'.", err)
+	}
+	if v := len(report); v != 1 {
+		t.Errorf("Expected Rollback to report 1 action. Got %d: %s", v, report)
+	}
+	if v := gitRun("rev-parse", "HEAD"); v != start {
+		t.Errorf("Expected repository restored to '%s'. Got '%s'.", start, v)
+	}
+	if v := gitRun("status", "--porcelain"); v != startStatus {
+		t.Errorf("Expected the user work to be restored as '%s'. Got '%s'.", startStatus, v)
+	}
+	if data, _ := ioutil.ReadFile(path.Join(repoPath, "Forjfile")); string(data) != "user update" {
+		t.Errorf("Expected the user Forjfile update to be restored. Got '%s'.", data)
+	}
+	if _, err := os.Stat(path.Join(repoPath, "plugin.yaml")); !os.IsNotExist(err) {
+		t.Error("Expected the driver file to be removed.")
+	}
+	backup := gitRun("branch", "--list", "--format=%(refname:short)", rollbackPrefix+"*")
+	if backup == "" {
+		t.Fatal("Expected a backup branch. Not found.")
+	}
+	if v := gitRun("show", backup+":plugin.yaml"); v != "partial" {
+		t.Errorf("Expected the driver file to be kept in the backup branch. Got '%s'.", v)
+	}
+	if v := gitRun("show", backup+"~1:Forjfile"); v != "updated" {
+		t.Errorf("Expected the driver commit to be kept in the backup branch. Got '%s'.", v)
+	}
+}
+
+func TestTransactionRollbackNoCommit(t *testing.T) {
+	t.Log("Expecting Rollback to restore a repository without commit.")
+
+	repoPath, gitRun, writeFile := newTestRepo(t)
+	defer os.RemoveAll(repoPath)
+	defer setTestIdentity()()
+
+	writeFile("Forjfile", "initial")
+
+	// Run the function
+	t.Log("Running Begin(\"infra\", repoPath)...")
+	tr, err := Begin("infra", repoPath)
+
+	// Test the result
+	if err != nil {
+		t.Fatalf("Expected Begin to return no error. Got '%s'.", err)
+	}
+	if tr.head != "" {
+		t.Errorf("Expected transaction to start without commit. Got '%s'.", tr.head)
+	}
+
+	// Simulate a driver which committed, then failed.
+	writeFile("Forjfile", "updated")
+	gitRun("add", "Forjfile")
+	gitRun("commit", "-q", "-m", "driver commit")
+
+	// Run the function
+	t.Log("Running tr.Rollback()...")
+	report, err := tr.Rollback()
+
+	// Test the result
+	if err != nil {
+		t.Fatalf("Expected Rollback to return no error. Got '%s'.", err)
+	}
+	if v := len(report); v != 1 {
+		t.Errorf("Expected Rollback to report 1 action. Got %d: %s", v, report)
+	}
+	if v, err := exec.Command("git", "-C", repoPath, "rev-parse", "--verify", "-q", "HEAD").Output(); err == nil {
+		t.Errorf("Expected the repository to have no commit. Got '%s'.", v)
+	}
+	if v := gitRun("status", "--porcelain"); v != "?? Forjfile" {
+		t.Errorf("Expected the Forjfile to be not controlled. Got '%s'.", v)
+	}
+	if data, _ := ioutil.ReadFile(path.Join(repoPath, "Forjfile")); string(data) != "initial" {
+		t.Errorf("Expected the Forjfile to be restored. Got '%s'.", data)
+	}
+
+	// Run the function
+	t.Log("Running tr.Rollback() again...")
+	report, err = tr.Rollback()
+
+	// Test the result
+	if err != nil {
+		t.Fatalf("Expected Rollback to return no error. Got '%s'.", err)
+	}
+	if len(report) != 1 || !strings.Contains(report[0], "nothing to restore") {
+		t.Errorf("Expected nothing to restore. Got %s", report)
+	}
+}
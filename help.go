@@ -16,6 +16,7 @@ But FORJJ is not DevOps if you do not help your teams to do DevOps (DevOps Cultu
 	forjj_infra_name_help     = "Upstream infra repository name. By default, the name is '<Organization>-infra'."
 	forjj_infra_upstream_help = "Required. Infra upstream instance name. Set 'none' if you do not want any upstream connected."
 	forjj_orga_name_help      = "Organization name. By default, the name is given by the workspace directory name. Warning! You cannot update it on an existing workspace"
+	forjj_output_help         = "Output format: 'text' or 'json'. With 'json', forjj prints a JSON document of the command result on the standard output. Logs are sent to the standard error. You can set FORJJ_OUTPUT as env."
 	forjj_creds_help          = "Credentials file. Used by plugins to collect credentials information. If you set driver credential flag on plugins, your workspace will collect them in your workspace 'forjj-creds.yml'."
 
 	create_action_help = "Create your Software factory.\n"
@@ -23,7 +24,7 @@ But FORJJ is not DevOps if you do not help your teams to do DevOps (DevOps Cultu
 	create_orga_help        = "organization workspace used to store repositories locally or in docker volume."
 	create_ssh_dir_help     = "PATH to a git ssh keys directory. It will be mounted as local path '/home/devops/.ssh' in the container."
 	create_no_maintain_help = "Do not instantiate at create time. (except infra upstream)"
-	create_forjfile_help    = "Create your Forge from a Forjfile path. Default is ."
+	create_forjfile_help    = "Create your Forge from a Forjfile model path or URL. Several models, comma separated, are merged in order. Default is ."
 	create_message_help     = "Commit message to apply."
 
 	infra_path_help         = "Path to your Forge infra repository. You can set it through FORJJ_INFRA as well."
@@ -38,15 +39,37 @@ But FORJJ is not DevOps if you do not help your teams to do DevOps (DevOps Cultu
 (for example from local jenkins to a mesos jenkins solution)
 `
 	update_orga_help        = "organization workspace used to store repositories locally or in docker volume."
+	createDeployToHelp      = "Deploy environment to create. Default is the PRO one."
+	validateDeployToHelp    = "Deploy environment to validate. Default is the PRO one."
+	validateAllHelp         = "Validate every deployment Forjfile merged with the master one, and report all errors found."
 	updateDeployToHelp      = "Deploy environment to update."
 	updateDeployPublishHelp = "Publish deployment generated source code to the deployment repository (commit/push)."
-	maintainDeployToHelp    = "Deploy environment to maintain."
+	maintainDeployToHelp    = "Deploy environment to maintain. Required, unless --all or --type is set."
+	updateAllHelp           = "Update all deployments, in deployment types order. (DEV, TEST and PRO by default)"
+	updateTypeHelp          = "Update all deployments of this type. Ex: DEV, TEST or PRO"
+	deploymentsFailFastHelp = "With --all or --type, stop on the first deployment failure. Next deployments are skipped."
+	maintainAllHelp         = "Maintain all deployments, in deployment types order. (DEV, TEST and PRO by default)"
+	maintainTypeHelp        = "Maintain all deployments of this type. Ex: DEV, TEST or PRO"
+	maintainResumeHelp      = "Resume the last maintain. Instances already maintained on the same Forjfile revision are skipped."
+	planDeployToHelp        = "Deploy environment to plan."
+	planMaintainHelp        = "Plan what maintain would do instead of update."
+	explainDeployToHelp     = "Deploy environment to explain."
+	explainPathHelp         = "Object instance or key to explain. Syntax : <object>/<instance>[/<key>]. Ex: repo/myrepo/flow"
+	promoteFromHelp         = "Deploy environment to promote from."
+	promoteDeployToHelp     = "Deploy environment to promote to. Its deployment Forjfile is updated."
+	promoteOnlyHelp         = "Promote only those values, comma separated. Syntax : <object>[/<instance>[/<key>]]. Ex: repo/myrepo,app"
+	promoteDryRunHelp       = "Only show what would be promoted."
+	flowDeployEnvHelp       = "forjj deployment environment used to apply flows. You can set 'FORJJ_DEPLOY_ENV' as environment variable."
+	flowExplainHelp         = "Apply flows on the in memory Forjfile and show what each flow task checked and set."
+	flowExplainRepoHelp     = "Repository to explain. Flows applied on the Forjfile are always explained."
+	parallelHelp            = "Maximum number of application instances to run at the same time. Instances run only when applications they depend on are done. Above 1, uncontrolled files are checked only in the plugin files directories. You can set FORJJ_PARALLEL as env."
 	flow_help               = "Define the default flow to apply to new repositories."
 
 	add_action_help    = "Add a component to your Software factory."
 	remove_action_help = "Remove a component from your Software factory."
 	rename_action_help = "Rename a component in your Software factory."
 	list_action_help   = "List components of your Software factory."
+	objectUpdateHelp   = "Run 'forjj update' on the current deployment once the Forjfile is committed."
 
 	maintain_action_help = "Used by your CI to update the infra from the 'infra' repository.\n"
 	maintain_option_file = "Forjj yaml file for plugins options"
@@ -61,10 +84,17 @@ But FORJJ is not DevOps if you do not help your teams to do DevOps (DevOps Cultu
 
 	default_flow_help = "Default flow to apply to repositories."
 
-	app_type_help   = "Driver category."
-	app_driver_help = "Driver name."
-	app_name_help   = "Application instance name. If not set, forjj will use the driver name."
-	app_list_help   = "List of application separated by comma. Syntax : category:driver[:instance]"
+	app_type_help     = "Driver category."
+	app_driver_help   = "Driver name."
+	app_name_help     = "Application instance name. If not set, forjj will use the driver name."
+	new_app_name_help = "New name of the application instance."
+	app_list_help     = "List of application separated by comma. Syntax : category:driver[:instance]"
 
 	val_act_help = "Verify your Forjfile definition."
+
+	explain_action_help = "Show the final value of Forjfile object keys for a deployment and where they come from."
+	promote_action_help = "Copy values of a deployment Forjfile to another deployment Forjfile, and commit it in the infra repository."
+	plan_action_help    = "Show what each driver would change on update (or maintain) of a deployment, without running any plugin."
+	flow_cmd_help       = "Help writing flows."
+	schema_action_help  = "Print the JSON Schema of your Forjfile, with keys defined by the plugins it uses."
 )
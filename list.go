@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"forjj/forjfile"
+	"forjj/utils"
+	"sort"
+	"strings"
+
+	"github.com/forj-oss/forjj-modules/trace"
+)
+
+// listAction display the list of repositories or applications declared in the Forjfile.
+// ex: forjj list repo
+func (a *Forj) listAction(action string) error {
+	actions := strings.Split(action, " ")
+	object := actions[len(actions)-1]
+
+	var err error
+	switch object {
+	case repo:
+		err = a.listRepos()
+	case app:
+		err = a.listApps()
+	default:
+		err = fmt.Errorf("Listing '%s' is not supported", object)
+	}
+	if err != nil {
+		return fmt.Errorf("Forjj list issue. %s", err)
+	}
+	return nil
+}
+
+// listedRepo is a repository reported by `forjj list repo`
+type listedRepo struct {
+	Name     string `json:"name"`
+	Title    string `json:"title,omitempty"`
+	Upstream string `json:"upstream,omitempty"`
+	Flow     string `json:"flow,omitempty"`
+}
+
+func (a *Forj) listRepos() error {
+	ffd := a.f.DeployForjfile()
+	if ffd == nil {
+		return fmt.Errorf("No Forjfile loaded")
+	}
+
+	repos := make(map[string]listedRepo)
+	for name, r := range ffd.Repos {
+		repo := listedRepo{Name: name}
+		repo.Title, _ = r.GetString(forjfile.FieldRepoTitle)
+		repo.Upstream, _ = r.GetString(forjfile.FieldRepoUpstream)
+		repo.Flow, _ = r.GetString(forjfile.FieldRepoFlow)
+		repos[name] = repo
+	}
+
+	if a.output.isJSON() {
+		names := make([]string, 0, len(repos))
+		for name := range repos {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		list := make([]listedRepo, 0, len(repos))
+		for _, name := range names {
+			list = append(list, repos[name])
+		}
+		a.output.setData(list)
+		return nil
+	}
+
+	// Create terminal array
+	array := utils.NewTerminalArray(len(repos), 4)
+
+	// Define Columns
+	array.SetCol(0, "Name")
+	array.SetCol(1, "Upstream")
+	array.SetCol(2, "Flow")
+	array.SetCol(3, "Title")
+
+	// Evaluate Array size
+	for name, repo := range repos {
+		array.EvalLine(name,
+			len(name),
+			len(repo.Upstream),
+			len(repo.Flow),
+			len(repo.Title))
+	}
+
+	a.output.printf("List of repositories: \n\n")
+
+	// Print the array
+	array.Print(
+		func(key string, compressedMax int) []interface{} {
+			repo, found := repos[key]
+			if !found {
+				return nil
+			}
+			return []interface{}{
+				key,
+				repo.Upstream,
+				repo.Flow,
+				utils.StringCompress(repo.Title, 0, compressedMax),
+			}
+		},
+	)
+
+	gotrace.Info("%d repositories found", len(repos))
+	return nil
+}
+
+// listedApp is an application reported by `forjj list app`
+type listedApp struct {
+	Name      string   `json:"name"`
+	Type      string   `json:"type"`
+	Driver    string   `json:"driver"`
+	Version   string   `json:"version,omitempty"`
+	DependsOn []string `json:"depends-on,omitempty"`
+}
+
+func (a *Forj) listApps() error {
+	apps := make(map[string]listedApp)
+	for name, app := range a.f.Apps() {
+		apps[name] = listedApp{
+			Name:      name,
+			Type:      app.Type,
+			Driver:    app.Driver,
+			Version:   app.Version,
+			DependsOn: app.GetDependsOn(),
+		}
+	}
+
+	if a.output.isJSON() {
+		names := make([]string, 0, len(apps))
+		for name := range apps {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		list := make([]listedApp, 0, len(apps))
+		for _, name := range names {
+			list = append(list, apps[name])
+		}
+		a.output.setData(list)
+		return nil
+	}
+
+	// Create terminal array
+	array := utils.NewTerminalArray(len(apps), 4)
+
+	// Define Columns
+	array.SetCol(0, "Name")
+	array.SetCol(1, "Type")
+	array.SetCol(2, "Driver")
+	array.SetCol(3, "Version")
+
+	// Evaluate Array size
+	for name, app := range apps {
+		array.EvalLine(name,
+			len(name),
+			len(app.Type),
+			len(app.Driver),
+			len(app.Version))
+	}
+
+	a.output.printf("List of applications: \n\n")
+
+	// Print the array
+	array.Print(
+		func(key string, compressedMax int) []interface{} {
+			app, found := apps[key]
+			if !found {
+				return nil
+			}
+			return []interface{}{
+				key,
+				app.Type,
+				app.Driver,
+				utils.StringCompress(app.Version, 0, compressedMax),
+			}
+		},
+	)
+
+	gotrace.Info("%d applications found", len(apps))
+	return nil
+}
+
+// workspaceAction runs the workspace command. With `--output json`, the workspace list is reported
+// in the JSON document.
+func (a *Forj) workspaceAction(action string) error {
+	actions := strings.Split(action, " ")
+	if !a.output.isJSON() || (len(actions) > 1 && actions[1] != "list") {
+		a.workspace.Action(action)
+		return nil
+	}
+
+	type listedData struct {
+		Value   string `json:"value"`
+		Default bool   `json:"default"`
+	}
+	data := make(map[string]listedData)
+	for key, value := range a.w.Data() {
+		data[key] = listedData{Value: value.Value, Default: value.IsDefault}
+	}
+	a.output.setData(data)
+	return nil
+}
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"log"
 	"fmt"
 	"forjj/creds"
 	"forjj/git"
@@ -10,12 +9,18 @@ import (
 	"github.com/forj-oss/forjj-modules/trace"
 )
 
-func (a *Forj) maintainAction(string) {
-	if err := a.Maintain(); err != nil {
-		log.Fatalf("Forjj maintain issue. %s", err)
+func (a *Forj) maintainAction(string) error {
+	var err error
+	if a.deployments != nil {
+		err = a.runOnDeployments()
+	} else {
+		err = a.Maintain()
+	}
+	if err != nil {
+		return fmt.Errorf("Forjj maintain issue. %s", err)
 	}
 	println("FORJJ - maintain ", a.w.GetString("organization"), " DONE") // , cmd.ProcessState.Sys().WaitStatus)
-
+	return nil
 }
 
 // Maintain call docker to create the Solution source code from scratch with validated parameters.
@@ -79,14 +84,27 @@ func (a *Forj) Maintain() error {
 }
 
 func (a *Forj) do_maintain() error {
-	// Loop on instances to maintain them
-	instances := a.define_drivers_execution_order()
-	for _, instance := range instances {
-		if err := a.doInstanceMaintain(instance); err != nil {
+	resume, _, _ := a.cli.GetBoolValue("_app", "forjj", maintainResumeF)
+	journal, err := a.loadMaintainJournal(resume)
+	if err != nil {
+		return err
+	}
+
+	// Maintain instances, following their dependencies.
+	return a.do_drivers_run(func(instance string) error {
+		if journal.completed(instance) {
+			gotrace.Info("'%s' already maintained on this Forjfile revision. Skipped.", instance)
+			return nil
+		}
+
+		journal.start(instance)
+		err := a.doInstanceMaintain(instance)
+		journal.end(instance, a.drivers[instance], err)
+		if err != nil {
 			return fmt.Errorf("Unable to maintain requested resources of %s. %s", instance, err)
 		}
-	}
-	return nil
+		return nil
+	})
 }
 
 func (a *Forj) doInstanceMaintain(instance string) error {
@@ -95,10 +113,10 @@ func (a *Forj) doInstanceMaintain(instance string) error {
 	}
 
 	gotrace.Trace("Start maintaining instance '%s'", instance)
-	if err := a.driver_init(instance); err != nil {
+	d, err := a.driver_init(instance)
+	if err != nil {
 		return err
 	}
-	d := a.CurrentPluginDriver
 
 	// Ensure remote upstream exists - calling upstream driver - maintain
 	// This will create/update the upstream service
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"forjj/drivers"
+	"forjj/utils"
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+
+	"github.com/forj-oss/forjj-modules/trace"
+)
+
+const maintainJournalDir = "maintain-journal" // Workspace directory where maintain runs are journaled.
+
+const (
+	journalStarted = "started"
+	journalDone    = "done"
+	journalFailed  = "failed"
+)
+
+// maintainJournal records the status of each instance maintained by a run.
+// It is saved in the workspace after each instance change, so that `forjj maintain --resume`
+// can skip instances already maintained for the same Forjfile revision.
+type maintainJournal struct {
+	Deployment string                          `json:"deployment"`
+	Revision   string                          `json:"revision"` // Checksum of the Forjfiles loaded. Committed or not.
+	Instances  map[string]*maintainJournalItem `json:"instances"`
+	file       string
+}
+
+// maintainJournalItem is the status of one instance in a maintain run.
+type maintainJournalItem struct {
+	Status    string    `json:"status"` // started, done or failed
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end,omitempty"`
+	StateCode int       `json:"state-code,omitempty"` // Plugin result state code.
+	Result    string    `json:"result,omitempty"`     // Plugin result status message.
+	Error     string    `json:"error,omitempty"`
+}
+
+// loadMaintainJournal returns the journal to use by the maintain run.
+//
+// If resume is true, the last journal is loaded, except if it was done for another Forjfile revision.
+// Otherwise a new journal is started.
+func (a *Forj) loadMaintainJournal(resume bool) (j *maintainJournal, err error) {
+	j = new(maintainJournal)
+	j.Deployment = a.f.GetDeployment()
+	j.file = path.Join(a.w.Path(), maintainJournalDir, j.Deployment+".json")
+
+	j.Revision = a.f.SourcesRevision()
+
+	j.Instances = make(map[string]*maintainJournalItem)
+	if !resume {
+		return
+	}
+
+	last := new(maintainJournal)
+	if djson, err := ioutil.ReadFile(j.file); os.IsNotExist(err) {
+		gotrace.Info("No previous maintain journal found. Nothing to resume.")
+		return j, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("Unable to read '%s'. %s", j.file, err)
+	} else if err = json.Unmarshal(djson, last); err != nil {
+		return nil, fmt.Errorf("Unable to load '%s'. %s", j.file, err)
+	}
+
+	if last.Revision != j.Revision {
+		gotrace.Warning("Forjfiles have changed since the last maintain run. All instances will be maintained.")
+		return
+	}
+	if last.Instances != nil {
+		j.Instances = last.Instances
+	}
+	return
+}
+
+// completed returns true if the instance was successfully maintained by a previous run.
+func (j *maintainJournal) completed(instance string) bool {
+	item, found := j.Instances[instance]
+	return found && item.Status == journalDone
+}
+
+// start records that the instance maintain has started.
+func (j *maintainJournal) start(instance string) {
+	j.Instances[instance] = &maintainJournalItem{
+		Status: journalStarted,
+		Start:  time.Now(),
+	}
+	j.save()
+}
+
+// end records the instance maintain result.
+func (j *maintainJournal) end(instance string, d *drivers.Driver, err error) {
+	item, found := j.Instances[instance]
+	if !found {
+		item = &maintainJournalItem{Start: time.Now()}
+		j.Instances[instance] = item
+	}
+	item.End = time.Now()
+	item.Status = journalDone
+	if err != nil {
+		item.Status = journalFailed
+		item.Error = err.Error()
+	}
+	if d != nil && d.Plugin != nil && d.Plugin.Result != nil {
+		item.StateCode = d.Plugin.Result.State_code
+		item.Result = d.Plugin.Result.Data.Status
+	}
+	j.save()
+}
+
+// save writes the journal in the workspace. Errors are reported as warnings.
+func (j *maintainJournal) save() {
+	if err := utils.EnsureDir(path.Dir(j.file), "maintain journal directory"); err != nil {
+		gotrace.Warning("Unable to save the maintain journal. %s", err)
+		return
+	}
+
+	djson, err := json.MarshalIndent(j, "", " ")
+	if err != nil {
+		gotrace.Warning("Unable to encode the maintain journal. %s", err)
+		return
+	}
+
+	if err = ioutil.WriteFile(j.file, djson, 0644); err != nil {
+		gotrace.Warning("Unable to save the maintain journal '%s'. %s", j.file, err)
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"forjj/forjfile"
+	"forjj/git"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/forj-oss/forjj-modules/trace"
+)
+
+// objectFields map cli object fields to Forjfile object fields, for objects updatable by add/change actions.
+var objectFields = map[string]map[string]string{
+	repo: {
+		"instance":      forjfile.FieldRepoUpstream,
+		"flow":          forjfile.FieldRepoFlow,
+		"repo_template": forjfile.FieldRepoTemplate,
+		"title":         forjfile.FieldRepoTitle,
+	},
+	app: {
+		"name":   "name",
+		"type":   "type",
+		"driver": "driver",
+	},
+}
+
+func (a *Forj) objectAction(action string) error {
+	if err := a.ObjectAction(action); err != nil {
+		return fmt.Errorf("Forjj %s issue. %s", action, err)
+	}
+	println("FORJJ - ", action, " DONE")
+	return nil
+}
+
+// ObjectAction updates the Forjfile from add/change/remove/rename actions on repo and app objects.
+//
+// ex: forjj add repos "github/myrepo:::My Repo,other_repo"
+//
+// The master Forjfile is updated, validated and committed in the infra repository.
+// With --update, `forjj update` is executed on the current deployment.
+func (a *Forj) ObjectAction(action string) error {
+	actions := strings.Split(action, " ")
+	if len(actions) < 2 {
+		return fmt.Errorf("Missing object to %s", action)
+	}
+	act := actions[0]
+	object := strings.TrimSuffix(actions[1], "s") // lists are named 'repos' or 'apps'
+
+	if _, found := objectFields[object]; !found {
+		return fmt.Errorf("Unable to %s '%s'. Only repo and app are supported", act, object)
+	}
+
+	if err := a.i.Use(a.f.InfraPath()); err != nil {
+		return fmt.Errorf("Invalid infra repository. %s", err)
+	}
+
+	instances := a.cli.GetObjectValues(object)
+	if len(instances) == 0 {
+		return fmt.Errorf("No %s given to %s", object, act)
+	}
+
+	names := make([]string, 0, len(instances))
+	for _, data := range instances {
+		name := data.GetString("name")
+		if name == "" && object == app {
+			name = data.GetString("driver")
+		}
+		if name == "" {
+			return fmt.Errorf("Unable to %s a %s without name", act, object)
+		}
+		exist := a.f.HasInstance(object, name)
+
+		switch act {
+		case add_act:
+			if exist {
+				return fmt.Errorf("%s '%s' already exists. Use 'forjj change %s'", object, name, object)
+			}
+			a.setObjectFromCli(object, name, data.Get)
+		case chg_act:
+			if !exist {
+				return fmt.Errorf("%s '%s' not found. Use 'forjj add %s'", object, name, object)
+			}
+			a.setObjectFromCli(object, name, data.Get)
+		case rem_act:
+			if !a.f.RemoveInstance(object, name) {
+				return fmt.Errorf("%s '%s' not found", object, name)
+			}
+		case ren_act:
+			newName := data.GetString("new_name")
+			if err := a.f.RenameInstance(object, name, newName); err != nil {
+				return err
+			}
+			name += " to " + newName
+		default:
+			return fmt.Errorf("Action '%s' is not supported", act)
+		}
+		gotrace.Trace("%s %s '%s' in the Forjfile.", act, object, name)
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Validate the new Forjfile before saving it
+	if err := a.f.BuildForjfileInMem(); err != nil {
+		return err
+	}
+	if err := a.ValidateForjfile(); err != nil {
+		return fmt.Errorf("Your Forjfile update is having issues. %s Nothing saved", err)
+	}
+
+	commitMsg := fmt.Sprintf("Forjfile: %s %s '%s'", act, object, strings.Join(names, "', '"))
+	if err := a.commitForjfile(commitMsg); err != nil {
+		return err
+	}
+
+	if doUpdate, found, _ := a.cli.GetBoolValue("_app", "forjj", objectUpdateF); found && doUpdate {
+		if a.f.GetDeployment() == "global" {
+			return fmt.Errorf("Forjfile committed, but unable to update. 'global' is not a valid deployment environment")
+		}
+		log.Printf("Forjfile updated. Running 'forjj update %s'...", a.f.GetDeployment())
+		return a.Update()
+	}
+	return nil
+}
+
+// setObjectFromCli sets the master Forjfile object instance fields given by the cli.
+func (a *Forj) setObjectFromCli(object, name string, get func(string) (string, bool, error)) {
+	for cliField, field := range objectFields[object] {
+		if v, found, _ := get(cliField); found && v != "" {
+			a.f.SetTo("global", "forjj", object, name, field, v)
+		}
+	}
+	if object == repo {
+		// Ensure a new repository is declared even without any other fields.
+		a.f.SetTo("global", "forjj", object, name, forjfile.FieldRepoName, name)
+	}
+}
+
+// commitForjfile saves the Forjfile and commits it in the infra repository.
+func (a *Forj) commitForjfile(commitMsg string) error {
+	if err := a.f.Save(); err != nil {
+		return fmt.Errorf("Unable to save the Forjfile. %s", err)
+	}
+
+	return git.RunInPath(a.f.InfraPath(), func() error {
+		if git.Add(a.f.Forjfiles_name()) > 0 {
+			return fmt.Errorf("Unable to add Forjfiles to the infra repository")
+		}
+		if err := git.Commit(commitMsg, false); err != nil {
+			return fmt.Errorf("Unable to commit the Forjfile. %s", err)
+		}
+		log.Printf("Infra repository: '%s' committed.", commitMsg)
+		return nil
+	})
+}
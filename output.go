@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"forjj/drivers"
+	"forjj/utils"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"sync"
+)
+
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// Output error codes.
+const (
+	outputErrCommand       = "command-failed" // The forjj command has failed.
+	outputErrPlugin        = "plugin-failed"  // A plugin has returned an error.
+	outputErrPluginAborted = "plugin-aborted" // A plugin has aborted its task. (requirement not met)
+)
+
+// forjOutput collects the result of a forjj command to report it as a JSON document, when
+// `--output json` is set.
+//
+// In json mode, the human output (see printf) is sent to the standard error, so that the standard output
+// contains only the JSON document.
+type forjOutput struct {
+	format string
+	lock   sync.Mutex
+
+	Command   string                     `json:"command"`
+	Status    string                     `json:"status"` // success or failed
+	Instances map[string]*outputInstance `json:"instances,omitempty"`
+	Files     map[string][]string        `json:"files,omitempty"` // Files written, by repository type. (source/deploy)
+	Repos     []string                   `json:"repos,omitempty"` // Repositories created/updated by plugins.
+	Data      interface{}                `json:"data,omitempty"`  // Command specific data. Ex: lists.
+	Errors    []outputError              `json:"errors,omitempty"`
+}
+
+// outputInstance is the result of the last plugin task of an instance.
+type outputInstance struct {
+	Driver       string `json:"driver"`
+	Action       string `json:"action"`
+	StateCode    int    `json:"state-code"`
+	Status       string `json:"status,omitempty"`
+	ErrorMessage string `json:"error-message,omitempty"`
+}
+
+// outputError is an error reported in the JSON document.
+type outputError struct {
+	Code     string `json:"code"`
+	Instance string `json:"instance,omitempty"`
+	Message  string `json:"message"`
+}
+
+// setFormat defines the output format and starts the JSON mode if requested.
+func (o *forjOutput) setFormat(format, command string) error {
+	switch format {
+	case "", outputText:
+		o.format = outputText
+		return nil
+	case outputJSON:
+	default:
+		return fmt.Errorf("Invalid output format '%s'. Valid ones are '%s' and '%s'", format, outputText, outputJSON)
+	}
+	if o.format == outputJSON {
+		return nil
+	}
+
+	o.format = outputJSON
+	o.Command = command
+	o.Instances = make(map[string]*outputInstance)
+	o.Files = make(map[string][]string)
+	utils.SetCmdOutput(os.Stderr)
+	return nil
+}
+
+// isJSON returns true if the output has to be a JSON document.
+func (o *forjOutput) isJSON() bool {
+	return o != nil && o.format == outputJSON
+}
+
+// writer returns where the human output is displayed. The standard error in json mode.
+func (o *forjOutput) writer() io.Writer {
+	if o.isJSON() {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// printf displays human output. See writer.
+func (o *forjOutput) printf(format string, a ...interface{}) {
+	fmt.Fprintf(o.writer(), format, a...)
+}
+
+// println displays a line of human output. See writer.
+func (o *forjOutput) println(a ...interface{}) {
+	fmt.Fprintln(o.writer(), a...)
+}
+
+// addPluginResult records the plugin result of an instance.
+func (o *forjOutput) addPluginResult(d *drivers.Driver, instance, action string) {
+	if !o.isJSON() || d == nil || d.Plugin == nil || d.Plugin.Result == nil {
+		return
+	}
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	result := d.Plugin.Result
+	o.Instances[instance] = &outputInstance{
+		Driver:       d.Name,
+		Action:       action,
+		StateCode:    result.State_code,
+		Status:       result.Data.Status,
+		ErrorMessage: result.Data.ErrorMessage,
+	}
+
+	for where, files := range result.Data.Files {
+		o.Files[where] = append(o.Files[where], files...)
+	}
+	for name := range result.Data.Repos {
+		o.Repos = append(o.Repos, name)
+	}
+
+	if result.State_code == 0 && result.Data.ErrorMessage == "" {
+		return
+	}
+	code := outputErrPlugin
+	if result.State_code == 419 {
+		code = outputErrPluginAborted
+	}
+	o.Errors = append(o.Errors, outputError{Code: code, Instance: instance, Message: result.Data.ErrorMessage})
+}
+
+// setData defines the command specific data to report.
+func (o *forjOutput) setData(data interface{}) {
+	if !o.isJSON() {
+		return
+	}
+	o.Data = data
+}
+
+// done reports the command result. It is called once, by the top level error handler.
+//
+// In json mode, it prints the JSON document. In text mode, err is logged.
+// The process exits with 1 if err is not nil.
+func (o *forjOutput) done(err error) {
+	if !o.isJSON() {
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	o.Status = "success"
+	if err != nil {
+		o.Status = "failed"
+		o.Errors = append(o.Errors, outputError{Code: outputErrCommand, Message: err.Error()})
+	}
+	sort.Strings(o.Repos)
+
+	djson, jsonErr := json.MarshalIndent(o, "", "  ")
+	if jsonErr != nil {
+		log.Fatalf("Unable to encode the JSON output. %s", jsonErr)
+	}
+	fmt.Fprintln(os.Stdout, string(djson))
+
+	if err != nil {
+		os.Exit(1)
+	}
+}
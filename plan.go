@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"forjj/creds"
+	"forjj/utils"
+	"strings"
+
+	"github.com/forj-oss/forjj-modules/trace"
+)
+
+func (a *Forj) planAction(string) error {
+	if err := a.Plan(); err != nil {
+		return fmt.Errorf("Forjj plan issue. %s", err)
+	}
+	println("FORJJ - plan ", a.w.GetString("organization"), " DONE")
+	return nil
+}
+
+// Plan reports what `forjj update` (or `forjj maintain` with --maintain) would send to each plugin instance.
+//
+// It prepares the in memory Forjfile as update/maintain do, build each plugin payload and compare it
+// to the last payload applied by the instance. No plugin service is started and nothing is written.
+func (a *Forj) Plan() error {
+	if _, err := a.w.Check_exist(); err != nil {
+		return fmt.Errorf("Invalid workspace. %s. Please create it with 'forjj create'", err)
+	}
+
+	action := upd_act
+	if v, found, _ := a.cli.GetBoolValue("_app", "forjj", planMaintainF); found && v {
+		action = maint_act
+	}
+
+	if err := a.ValidateForjfile(); err != nil {
+		return fmt.Errorf("Your Forjfile is having issues. %s Try to fix and retry", err)
+	}
+
+	// Build in memory representation from source files loaded.
+	if err := a.f.BuildForjfileInMem(); err != nil {
+		return err
+	}
+
+	ffd := a.f.InMemForjfile()
+
+	if err := a.DefineDeployRepositories(ffd, true); err != nil {
+		return fmt.Errorf("Issues to automatically add your deployment repositories. %s", err)
+	}
+
+	// Defining information about current deployment repository
+	a.defineDeployContext()
+
+	// Load flow identified by Forjfile source with missing repos.
+	if err := a.FlowInit(); err != nil {
+		return err
+	}
+
+	if err := a.define_infra_upstream(); err != nil {
+		return fmt.Errorf("Unable to identify a valid infra repository upstream. %s", err)
+	}
+
+	if action == maint_act {
+		if err := a.scanCreds(ffd, creds.Global, true); err != nil {
+			return err
+		}
+	}
+
+	// Apply the flow to the inMemForjfile
+	if err := a.FlowApply(); err != nil {
+		return fmt.Errorf("Unable to apply flows. %s", err)
+	}
+
+	// Set plugin defaults for objects added dynamically in the in memory Forjfile.
+	if err := a.scanAndSetDefaults(ffd, creds.Global); err != nil {
+		return fmt.Errorf("Unable to plan. Global dispatch issue. %s", err)
+	}
+
+	a.output.printf("Plan of '%s' on '%s' deployment:\n", action, a.f.GetDeployment())
+
+	g, err := a.define_drivers_execution_graph()
+	if err != nil {
+		return err
+	}
+	instances, err := g.order()
+	if err != nil {
+		return err
+	}
+
+	iChanged := 0
+	for _, instance := range instances {
+		if instance == "none" {
+			continue
+		}
+		changed, err := a.planInstance(instance, action)
+		if err != nil {
+			return fmt.Errorf("Unable to plan '%s'. %s", instance, err)
+		}
+		if changed {
+			iChanged++
+		}
+	}
+
+	gotrace.Info("%d/%d instances would be updated by '%s'.", iChanged, len(instances), action)
+	return nil
+}
+
+// planInstance display the changes between the payload the instance would receive and the last one applied.
+func (a *Forj) planInstance(instance, action string) (changed bool, _ error) {
+	d, err := a.driver_init(instance)
+	if err != nil {
+		return false, err
+	}
+
+	payload, err := a.buildPluginPayload(d, instance, action)
+	if err != nil {
+		return false, err
+	}
+
+	state, err := a.newPluginState(d, payload)
+	if err != nil {
+		return false, err
+	}
+
+	applied, found, err := a.lastPluginState(instance, action)
+	if err != nil {
+		return false, err
+	}
+
+	changes := state.diff(applied)
+
+	a.output.printf("\n=== %s (%s/%s)\n", instance, d.DriverType, d.Name)
+	if !found {
+		a.output.println("Never applied from this workspace. All keys will be sent.")
+	}
+	if len(changes) == 0 {
+		a.output.println("No changes.")
+		return false, nil
+	}
+
+	// Create terminal array
+	array := utils.NewTerminalArray(len(changes), 4)
+
+	// Define Columns
+	array.SetCol(0, "Key")
+	array.SetCol(1, "Change")
+	array.SetCol(2, "Applied")
+	array.SetCol(3, "Planned")
+
+	// Evaluate Array size
+	for key, change := range changes {
+		array.EvalLine(key,
+			len(key),
+			len(change.status),
+			len(planValue(change.before)),
+			len(planValue(change.after)))
+	}
+
+	array.Print(
+		func(key string, compressedMax int) []interface{} {
+			change, found := changes[key]
+			if !found {
+				return nil
+			}
+			return []interface{}{
+				key,
+				change.status,
+				planValue(change.before),
+				utils.StringCompress(planValue(change.after), 0, compressedMax),
+			}
+		},
+	)
+	return true, nil
+}
+
+// planValue returns a value displayable on one line.
+func planValue(value string) string {
+	return strings.Replace(value, "\n", "\\n", -1)
+}
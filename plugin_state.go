@@ -0,0 +1,243 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"forjj/drivers"
+	"forjj/utils"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/forj-oss/forjj-modules/trace"
+	"github.com/forj-oss/goforjj"
+)
+
+const (
+	pluginStateDir     = "plugins-state" // Workspace directory where applied plugin payloads are saved.
+	pluginStateKeyFile = "state.key"     // Key used to hash secure values, in pluginStateDir.
+)
+
+const (
+	stateKeyAdded   = "added"
+	stateKeyRemoved = "removed"
+	stateKeyUpdated = "updated"
+)
+
+// pluginState is a flat representation of a plugin payload.
+// Each key is a '/' separated path to a value sent to the plugin. Ex: objects/repo/myrepo/title
+type pluginState map[string]string
+
+// pluginStateChange describe how a payload key changed between 2 plugin states.
+type pluginStateChange struct {
+	status string // added, removed or updated
+	before string
+	after  string
+}
+
+// pluginStateKey is the workspace key used to hash secure values of plugin states.
+// It is loaded, or created, once.
+type pluginStateKey struct {
+	once sync.Once
+	key  []byte
+	err  error
+}
+
+// newPluginState flatten the plugin payload given.
+//
+// Secure values are replaced by a HMAC with the workspace key, so that forjj can detect any update
+// without storing secrets in the workspace.
+func newPluginState(d *drivers.Driver, payload *goforjj.PluginReqData, key []byte) (pluginState, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to encode the plugin payload. %s", err)
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("Unable to decode the plugin payload. %s", err)
+	}
+
+	state := make(pluginState)
+	state.flatten("", doc, pluginSecureFlags(d), key, false)
+	return state, nil
+}
+
+// pluginSecureFlags returns the list of flags declared as secure by the plugin.
+// Object group flags are named <group>-<flag>.
+func pluginSecureFlags(d *drivers.Driver) (secure map[string]bool) {
+	secure = make(map[string]bool)
+	if d == nil || d.Plugin == nil {
+		return
+	}
+	for _, flags := range d.Plugin.Yaml.Tasks {
+		for flagName, flag := range flags {
+			if flag.Options.Secure {
+				secure[flagName] = true
+			}
+		}
+	}
+	for _, object := range d.Plugin.Yaml.Objects {
+		for flagName, flag := range object.Flags {
+			if flag.Options.Secure {
+				secure[flagName] = true
+			}
+		}
+		for group, groupDef := range object.Groups {
+			for flagName, flag := range groupDef.Flags {
+				if flag.Options.Secure {
+					secure[group+"-"+flagName] = true
+				}
+			}
+		}
+	}
+	return
+}
+
+// flatten add all leaf values of doc in the state, under the key path given.
+func (s pluginState) flatten(keyPath string, doc interface{}, secure map[string]bool, key []byte, isSecure bool) {
+	switch value := doc.(type) {
+	case map[string]interface{}:
+		for name, element := range value {
+			s.flatten(path.Join(keyPath, name), element, secure, key, isSecure || secure[name] || strings.EqualFold(name, "creds"))
+		}
+	case []interface{}:
+		for index, element := range value {
+			s.flatten(path.Join(keyPath, strconv.Itoa(index)), element, secure, key, isSecure)
+		}
+	case nil:
+		return
+	default:
+		v := fmt.Sprint(value)
+		if isSecure && v != "" {
+			mac := hmac.New(sha256.New, key)
+			mac.Write([]byte(v))
+			v = fmt.Sprintf("hmac-sha256:%x", mac.Sum(nil))
+		}
+		s[keyPath] = v
+	}
+}
+
+// diff compares the state with the applied one and returns the list of changed keys.
+func (s pluginState) diff(applied pluginState) (changes map[string]pluginStateChange) {
+	changes = make(map[string]pluginStateChange)
+	for key, value := range s {
+		if before, found := applied[key]; !found {
+			changes[key] = pluginStateChange{status: stateKeyAdded, after: value}
+		} else if before != value {
+			changes[key] = pluginStateChange{status: stateKeyUpdated, before: before, after: value}
+		}
+	}
+	for key, before := range applied {
+		if _, found := s[key]; !found {
+			changes[key] = pluginStateChange{status: stateKeyRemoved, before: before}
+		}
+	}
+	return
+}
+
+// save writes the state in the json file given.
+func (s pluginState) save(file string) error {
+	if err := utils.EnsureDir(path.Dir(file), "plugin state directory"); err != nil {
+		return err
+	}
+
+	djson, err := json.MarshalIndent(s, "", " ")
+	if err != nil {
+		return fmt.Errorf("Issue to encode in json. %s", err)
+	}
+
+	if err = ioutil.WriteFile(file, djson, 0600); err != nil {
+		return fmt.Errorf("Unable to create/update '%s'. %s", file, err)
+	}
+	gotrace.Trace("Plugin state '%s' saved.", file)
+	return nil
+}
+
+// loadPluginState reads a state previously saved. found is false if the state file doesn't exist.
+func loadPluginState(file string) (state pluginState, found bool, err error) {
+	djson, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("Unable to read '%s'. %s", file, err)
+	}
+
+	state = make(pluginState)
+	if err = json.Unmarshal(djson, &state); err != nil {
+		return nil, false, fmt.Errorf("Unable to load '%s'. %s", file, err)
+	}
+	return state, true, nil
+}
+
+// pluginStateFile returns the workspace file path where the last payload applied
+// by an instance for an action is saved.
+func (a *Forj) pluginStateFile(instance, action string) string {
+	return path.Join(a.w.Path(), pluginStateDir, a.f.GetDeployment(), instance, action+".json")
+}
+
+// get returns the key saved in the file given. The key is created if the file doesn't exist.
+func (k *pluginStateKey) get(file string) ([]byte, error) {
+	k.once.Do(func() {
+		if k.key, k.err = ioutil.ReadFile(file); k.err == nil || !os.IsNotExist(k.err) {
+			return
+		}
+		if k.err = utils.EnsureDir(path.Dir(file), "plugin state directory"); k.err != nil {
+			return
+		}
+		k.key = make([]byte, 32)
+		if _, k.err = rand.Read(k.key); k.err != nil {
+			return
+		}
+		k.err = ioutil.WriteFile(file, k.key, 0600)
+	})
+	if k.err != nil {
+		return nil, fmt.Errorf("Unable to load the plugin state key '%s'. %s", file, k.err)
+	}
+	return k.key, nil
+}
+
+// newPluginState returns the state of the payload given, with secure values hashed with the workspace key.
+func (a *Forj) newPluginState(d *drivers.Driver, payload *goforjj.PluginReqData) (pluginState, error) {
+	key, err := a.stateKey.get(path.Join(a.w.Path(), pluginStateDir, pluginStateKeyFile))
+	if err != nil {
+		return nil, err
+	}
+	return newPluginState(d, payload, key)
+}
+
+// savePluginState keeps the payload successfully applied by the plugin instance.
+// This state is used by `forjj plan` to report what an update or maintain would change.
+//
+// During a create or update transaction, the state is saved only when the transaction is committed.
+func (a *Forj) savePluginState(d *drivers.Driver, instance, action string, payload *goforjj.PluginReqData) {
+	state, err := a.newPluginState(d, payload)
+	if err == nil {
+		file := a.pluginStateFile(instance, action)
+		if a.transaction.keepPluginState(file, state) {
+			return
+		}
+		err = state.save(file)
+	}
+	if err != nil {
+		gotrace.Warning("Unable to save '%s' %s state. %s", instance, action, err)
+	}
+}
+
+// lastPluginState returns the last state applied by an instance for the action given.
+// If the action was never applied, the create state is used as reference.
+func (a *Forj) lastPluginState(instance, action string) (state pluginState, found bool, err error) {
+	for _, stateAction := range []string{action, cr_act} {
+		if state, found, err = loadPluginState(a.pluginStateFile(instance, stateAction)); found || err != nil {
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"forjj/drivers"
+	"strings"
+	"testing"
+
+	"github.com/forj-oss/goforjj"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPluginStateFlatten(t *testing.T) {
+	assert := assert.New(t)
+
+	secureFlag := goforjj.YamlFlag{Options: goforjj.YamlFlagOptions{Secure: true}}
+	d := &drivers.Driver{Plugin: &goforjj.Driver{Yaml: goforjj.YamlPlugin{Objects: map[string]goforjj.YamlObject{
+		"app": {
+			Flags:  map[string]goforjj.YamlFlag{"token": secureFlag, "url": {}},
+			Groups: map[string]goforjj.YamlObjectGroup{"hook": {Flags: map[string]goforjj.YamlFlag{"secret": secureFlag}}},
+		},
+	}}}}
+	doc := map[string]interface{}{
+		"objects": map[string]interface{}{
+			"app": map[string]interface{}{
+				"github": map[string]interface{}{
+					"token":       "secret",
+					"url":         "https://github.com",
+					"hook-secret": "secret",
+				},
+			},
+		},
+	}
+
+	/*************************************/
+	testCase := "when the payload has secure object and group flags"
+
+	state := make(pluginState)
+	state.flatten("", doc, pluginSecureFlags(d), []byte("key"), false)
+	assert.Equalf("https://github.com", state["objects/app/github/url"], "Expect url in clear %s", testCase)
+	for _, key := range []string{"objects/app/github/token", "objects/app/github/hook-secret"} {
+		assert.Truef(strings.HasPrefix(state[key], "hmac-sha256:"), "Expect %s to be hashed %s", key, testCase)
+		assert.NotContainsf(state[key], "secret", "Expect %s to not be stored %s", key, testCase)
+	}
+
+	/*************************************/
+	testCase = "when the workspace key is different"
+
+	other := make(pluginState)
+	other.flatten("", doc, pluginSecureFlags(d), []byte("other key"), false)
+	assert.NotEqualf(state["objects/app/github/token"], other["objects/app/github/token"], "Expect a different hash %s", testCase)
+}
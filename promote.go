@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"forjj/forjfile"
+	"strings"
+
+	"github.com/forj-oss/forjj-modules/trace"
+)
+
+func (a *Forj) promoteAction(string) error {
+	if err := a.Promote(); err != nil {
+		return fmt.Errorf("Forjj promote issue. %s", err)
+	}
+	return nil
+}
+
+// promoteResult is the result of `forjj promote`, reported with `--output json`.
+type promoteResult struct {
+	From    string                  `json:"from"`
+	To      string                  `json:"to"`
+	Changes []forjfile.ForjfileDiff `json:"changes"`
+	// Creds keys defined in the source deployment and missing in the target one. Values are never reported.
+	MissingCreds []string `json:"missing-creds,omitempty"`
+	Committed    bool     `json:"committed"`
+}
+
+// Promote copies values defined in a deployment Forjfile to the current deployment Forjfile.
+//
+// Only values defined in the source deployment Forjfile which are different or missing in the target
+// are promoted. The target deployment Forjfile is then saved and committed in the infra repository.
+// Creds are not copied. Creds keys missing in the target deployment are reported.
+func (a *Forj) Promote() error {
+	from, _, _, _ := a.cli.GetStringValue("_app", "forjj", promoteFromArg)
+	to := a.f.GetDeployment()
+	if from == to {
+		return fmt.Errorf("Unable to promote '%s' to itself", from)
+	}
+	fromDeploy, found := a.f.GetADeployment(from)
+	if !found {
+		return fmt.Errorf("Unknown deployment environment '%s'. Use one defined in your Forjfile", from)
+	}
+	toDeploy, found := a.f.GetADeployment(to)
+	if !found {
+		return fmt.Errorf("Unknown deployment environment '%s'. Use one defined in your Forjfile", to)
+	}
+	if deployTypes := a.f.DeploymentTypes(); deployTypes.Rank(toDeploy.Type) < deployTypes.Rank(fromDeploy.Type) {
+		gotrace.Warning("'%s' (%s) comes before '%s' (%s) in the deployment types order (%s).",
+			to, toDeploy.Type, from, fromDeploy.Type, strings.Join(deployTypes.Names(), ", "))
+	}
+	if toDeploy.Details == nil {
+		return fmt.Errorf("The '%s' deployment Forjfile is not loaded", to)
+	}
+
+	only, _, _, _ := a.cli.GetStringValue("_app", "forjj", promoteOnlyF)
+	result := promoteResult{
+		From:    from,
+		To:      to,
+		Changes: promoteFilter(fromDeploy.Details.Diff(toDeploy.Details), only),
+	}
+
+	if err := a.s.LoadEnv(from); err != nil {
+		gotrace.Warning("Unable to load '%s' credentials. %s", from, err)
+	}
+	toCreds := make(map[string]bool)
+	for _, key := range a.s.Keys(to) {
+		toCreds[key] = true
+	}
+	for _, key := range a.s.Keys(from) {
+		if !toCreds[key] && promoteSelected(key, only) {
+			result.MissingCreds = append(result.MissingCreds, key)
+		}
+	}
+
+	dryRun, _, _ := a.cli.GetBoolValue("_app", "forjj", promoteDryRunF)
+	if !dryRun && len(result.Changes) > 0 {
+		for _, change := range result.Changes {
+			toDeploy.Details.Set("promote", change.Object, change.Instance, change.Key, change.From)
+		}
+		if err := a.commitForjfile(fmt.Sprintf("Forjfile: promote '%s' to '%s'", from, to)); err != nil {
+			return err
+		}
+		result.Committed = true
+	}
+
+	if a.output.isJSON() {
+		a.output.setData(result)
+		return nil
+	}
+
+	if len(result.Changes) == 0 {
+		a.output.printf("Nothing to promote from '%s' to '%s'.\n", from, to)
+	} else {
+		a.output.printf("Promote '%s' to '%s':\n", from, to)
+	}
+	for _, change := range result.Changes {
+		if change.New {
+			a.output.printf("+ %s: '%s'\n", change.Path(), change.From)
+		} else {
+			a.output.printf("~ %s: '%s' => '%s'\n", change.Path(), change.To, change.From)
+		}
+	}
+	for _, key := range result.MissingCreds {
+		a.output.printf("! %s is defined in '%s' credentials but not in '%s'. Use 'forjj secrets set' to define it.\n", key, from, to)
+	}
+	if dryRun && len(result.Changes) > 0 {
+		a.output.println("Dry run. Nothing has been changed.")
+	}
+	return nil
+}
+
+// promoteFilter returns changes selected by --only.
+func promoteFilter(changes []forjfile.ForjfileDiff, only string) (ret []forjfile.ForjfileDiff) {
+	ret = make([]forjfile.ForjfileDiff, 0, len(changes))
+	for _, change := range changes {
+		if promoteSelected(change.Path(), only) {
+			ret = append(ret, change)
+		}
+	}
+	return
+}
+
+// promoteSelected returns true if the object/instance/key path is selected by --only.
+// Without --only, everything is selected.
+func promoteSelected(path, only string) bool {
+	if only == "" {
+		return true
+	}
+	for _, selected := range strings.Split(only, ",") {
+		selected = strings.Trim(strings.TrimSpace(selected), "/")
+		if selected == "" {
+			continue
+		}
+		if path == selected || strings.HasPrefix(path, selected+"/") {
+			return true
+		}
+	}
+	return false
+}
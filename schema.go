@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"forjj/forjfile"
+	"sort"
+
+	"github.com/forj-oss/goforjj"
+)
+
+func (a *Forj) schemaAction(string) error {
+	if err := a.Schema(); err != nil {
+		return fmt.Errorf("Forjj schema issue. %s", err)
+	}
+	return nil
+}
+
+// Schema prints the JSON Schema of the Forjfile.
+//
+// The schema is built from the forjj core structs and from objects flags of each plugin used by the Forjfile.
+// With `--output json`, the schema is reported in `data`.
+func (a *Forj) Schema() error {
+	schema, err := a.forjfileSchema()
+	if err != nil {
+		return err
+	}
+
+	if a.output.isJSON() {
+		a.output.setData(schema)
+		return nil
+	}
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Unable to encode the Forjfile schema. %s", err)
+	}
+	a.output.println(string(data))
+	return nil
+}
+
+// forjfileSchema returns the Forjfile schema, with keys defined by plugins of the Forjfile applications.
+//
+// Objects keys defined by plugins are added to the core objects (app, repo, user, group). Other plugins
+// objects are added as new Forjfile objects. As `forjj validate` does, keys not defined by forjj or plugins
+// are then rejected.
+func (a *Forj) forjfileSchema() (*forjfile.JSONSchema, error) {
+	ffd := a.f.DeployForjfile()
+	if ffd == nil {
+		return nil, fmt.Errorf("No Forjfile loaded")
+	}
+	schema := forjfile.NewForjfileSchema()
+
+	for _, name := range sortedNames(ffd.GetInstances("app")) {
+		app := ffd.Apps[name]
+		if err := a.loadAppDriver(app); err != nil {
+			return nil, err
+		}
+		d := a.drivers[name]
+		if d == nil || d.Plugin == nil {
+			continue
+		}
+		if o, found := d.Plugin.Yaml.Objects[goforjj.ObjectApp]; found {
+			// Application keys depend on the driver of each instance.
+			def, _ := schema.Definition(goforjj.ObjectApp)
+			instance := def.Copy()
+			instance.Description = fmt.Sprintf("'%s' application, managed by the '%s' plugin.", name, app.Driver)
+			instance.AdditionalProperties = false
+			addPluginKeys(instance, o)
+			schema.Properties["applications"].AddKey(name, instance, false)
+		}
+		for _, object := range sortedPluginObjects(d.Plugin.Yaml.Objects) {
+			if object == goforjj.ObjectApp {
+				continue
+			}
+			o := d.Plugin.Yaml.Objects[object]
+			if def, found := schema.Definition(object); found {
+				addPluginKeys(def, o)
+				continue
+			}
+			if _, found := schema.Properties[object]; found {
+				continue // Not a Forjfile object. Ex: infra
+			}
+			def := &forjfile.JSONSchema{Type: "object", Description: o.Help}
+			addPluginKeys(def, o)
+			schema.AddObject(object, def)
+		}
+	}
+
+	for _, object := range []string{"repo", "user", "group", forjfile.SchemaDeployForjfile} {
+		if def, found := schema.Definition(object); found {
+			def.AdditionalProperties = false
+		}
+	}
+	schema.AdditionalProperties = false
+	return schema, nil
+}
+
+// addPluginKeys adds plugin object flags to an object schema. Group flags are named `<group>-<flag>`.
+//
+// Secure flags are stored in forjj credentials and required flags can be given from the cli. So they are not
+// required in the Forjfile. Only required flags without default value, which are not secure, are.
+func addPluginKeys(object *forjfile.JSONSchema, o goforjj.YamlObject) {
+	add := func(name string, flag goforjj.YamlFlag) {
+		key := &forjfile.JSONSchema{
+			Type:        "string",
+			Description: flag.Help,
+			Default:     flag.Options.Default,
+			WriteOnly:   flag.Options.Secure,
+		}
+		if flag.FormatRegexp != "" {
+			key.Pattern = "^(" + flag.FormatRegexp + ")$"
+		}
+		// The instance name is given by the Forjfile object key.
+		required := flag.Options.Required && !flag.Options.Secure && flag.Options.Default == "" && name != o.Identified_by_flag
+		object.AddKey(name, key, required)
+	}
+	for _, name := range sortedPluginFlags(o.Flags) {
+		add(name, o.Flags[name])
+	}
+	for _, group := range sortedPluginGroups(o.Groups) {
+		for _, name := range sortedPluginFlags(o.Groups[group].Flags) {
+			add(group+"-"+name, o.Groups[group].Flags[name])
+		}
+	}
+}
+
+func sortedPluginObjects(objects map[string]goforjj.YamlObject) (ret []string) {
+	for name := range objects {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return
+}
+
+func sortedPluginFlags(flags map[string]goforjj.YamlFlag) (ret []string) {
+	for name := range flags {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return
+}
+
+func sortedPluginGroups(groups map[string]goforjj.YamlObjectGroup) (ret []string) {
+	for name := range groups {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return
+}
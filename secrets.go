@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/alecthomas/kingpin"
@@ -44,19 +45,23 @@ func (s *secrets) init(app *kingpin.Application) {
 	s.unset.init(s.secrets, &s.common)
 }
 
-func (s *secrets) action(action string) {
+func (s *secrets) action(action string) (err error) {
 	actions := strings.Split(action, " ")
 	switch actions[1] {
 	case "list":
 		s.list.showList()
 	case "set":
-		s.set.doSet()
+		err = s.set.doSet()
 	case "edit":
-		s.edit.doEdit()
+		err = s.edit.doEdit()
 	case "unset":
-		s.unset.doUnset()
+		err = s.unset.doUnset()
 	case "show":
 	}
+	if err != nil {
+		return fmt.Errorf("Forjj secrets %s issue. %s", actions[1], err)
+	}
+	return
 }
 
 // DefineContext define cli Context to permit ParseContext to retrieve
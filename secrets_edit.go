@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"forjj/creds"
 	"forjj/scandrivers"
 	"io/ioutil"
@@ -37,7 +38,7 @@ func (s *secretsEdit) init(parent *kingpin.CmdClause, common *secretsCommon) {
 
 // doEdit register a password to the path given.
 // Only supported path are recognized.
-func (s *secretsEdit) doEdit() {
+func (s *secretsEdit) doEdit() error {
 	ffd := forj_app.f.InMemForjfile()
 
 	scan := scandrivers.NewScanDrivers(ffd, forj_app.drivers)
@@ -63,8 +64,7 @@ func (s *secretsEdit) doEdit() {
 	scan.DoScanDriversObject()
 
 	if _, found := s.elements[*s.key]; !found {
-		gotrace.Error("'%s' is not a valid secret path. check with `forjj secrets`", *s.key)
-		return
+		return fmt.Errorf("'%s' is not a valid secret path. check with `forjj secrets`", *s.key)
 	}
 
 	if *s.editor == "" {
@@ -73,8 +73,7 @@ func (s *secretsEdit) doEdit() {
 
 	tmpFile, err := ioutil.TempFile("/tmp", "forjj-")
 	if err != nil {
-		gotrace.Error("Unable to create temporary file in /tmp. %s. Exiting.", err)
-		return
+		return fmt.Errorf("Unable to create temporary file in /tmp. %s", err)
 	}
 
 	fileName := tmpFile.Name()
@@ -86,38 +85,34 @@ func (s *secretsEdit) doEdit() {
 
 	_, err = tmpFile.WriteString(s.password)
 	if err != nil {
-		gotrace.Error("Unable to write temporary file in /tmp. %s. Exiting.", err)
-		return
+		return fmt.Errorf("Unable to write temporary file in /tmp. %s", err)
 	}
 	tmpFile.Close()
 
 	cmd := exec.Command(*s.editor, fileName)
 	if !*s.noTerminalSetup {
 		if !terminal.IsTerminal(int(os.Stdin.Fd())) {
-			gotrace.Error("Unable to edit %s. Not a terminal. Exiting.", *s.key)
-			return
+			return fmt.Errorf("Unable to edit %s. Not a terminal", *s.key)
 		}
 		cmd.Stdin = os.Stdin
-		cmd.Stdout = os.Stdout
+		cmd.Stdout = forj_app.output.writer()
 		cmd.Stderr = os.Stderr
 	}
 	err = cmd.Run()
 
 	if err != nil {
-		gotrace.Error("Unable to start the editor %s on file %s. %s. Exiting.", *s.editor, fileName, err)
-		return
+		return fmt.Errorf("Unable to start the editor %s on file %s. %s", *s.editor, fileName, err)
 	}
 
 	if data, err := ioutil.ReadFile(fileName); err != nil {
-		gotrace.Error("Unable to read the editor file %s. %s. Exiting.", fileName, err)
-		return
+		return fmt.Errorf("Unable to read the editor file %s. %s", fileName, err)
 	} else {
 		s.password = strings.Trim(string(data), " \n")
 	}
 
 	if len(s.password) == 0 {
 		gotrace.Info("File is empty. Update ignored.")
-		return
+		return nil
 	}
 	v := goforjj.ValueStruct{}
 	v.Set(s.password)
@@ -127,9 +122,12 @@ func (s *secretsEdit) doEdit() {
 	}
 	if !forj_app.s.SetObjectValue(env, "forjj", keyPath[0], keyPath[1], keyPath[2], &v) {
 		gotrace.Info("'%s' secret text not updated.", *s.key)
-		return
+		return nil
 	}
 
-	forj_app.s.SaveEnv(env)
+	if err := forj_app.s.SaveEnv(env); err != nil {
+		return fmt.Errorf("Unable to save the '%s' deployment environment secrets. %s", env, err)
+	}
 	gotrace.Info("'%s' secret text saved in '%s' deployment environment.", *s.key, env)
+	return nil
 }
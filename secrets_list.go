@@ -1,9 +1,9 @@
 package main
 
 import (
-	"fmt"
 	"forjj/scandrivers"
 	"forjj/utils"
+	"sort"
 	"strings"
 
 	"github.com/alecthomas/kingpin"
@@ -54,6 +54,11 @@ func (l *secretsList) showList() {
 	})
 	scan.DoScanDriversObject()
 
+	if forj_app.output.isJSON() {
+		l.setOutputData()
+		return
+	}
+
 	// Create terminal array
 	array := utils.NewTerminalArray(len(l.elements), 4)
 
@@ -76,7 +81,7 @@ func (l *secretsList) showList() {
 			len(value))
 	}
 
-	fmt.Printf("List of secrets in forjj: (Deployment environment = '%s')\n\n", forj_app.f.GetDeployment())
+	forj_app.output.printf("List of secrets in forjj: (Deployment environment = '%s')\n\n", forj_app.f.GetDeployment())
 
 	// Print the array
 	iFound := 0
@@ -110,3 +115,30 @@ func (l *secretsList) showList() {
 	gotrace.Info("%d/%d secrets found", iFound, iTotal)
 
 }
+
+// setOutputData reports the list of secrets in the JSON output. Values are reported only with --show.
+func (l *secretsList) setOutputData() {
+	type listedSecret struct {
+		Path        string `json:"path"`
+		Environment string `json:"environment"`
+		Source      string `json:"source"`
+		Found       bool   `json:"found"`
+		Secret      string `json:"secret,omitempty"`
+	}
+
+	list := make([]listedSecret, 0, len(l.elements))
+	for _, info := range l.elements {
+		secret := listedSecret{
+			Path:        info.keyPath,
+			Environment: info.env,
+			Source:      info.source,
+			Found:       info.found,
+		}
+		if *l.show {
+			secret.Secret = info.value
+		}
+		list = append(list, secret)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Path < list[j].Path })
+	forj_app.output.setData(list)
+}
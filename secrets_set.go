@@ -32,7 +32,7 @@ func (s *secretsSet) init(parent *kingpin.CmdClause, common *secretsCommon) {
 
 // doSet register a password to the path given.
 // Only supported path are recognized.
-func (s *secretsSet) doSet() {
+func (s *secretsSet) doSet() error {
 	ffd := forj_app.f.InMemForjfile()
 
 	scan := scandrivers.NewScanDrivers(ffd, forj_app.drivers)
@@ -62,18 +62,16 @@ func (s *secretsSet) doSet() {
 	scan.DoScanDriversObject()
 
 	if _, found := s.elements[*s.key]; !found {
-		gotrace.Error("'%s' is not a valid secret path. check with `forjj secrets`", *s.key)
-		return
+		return fmt.Errorf("'%s' is not a valid secret path. check with `forjj secrets`", *s.key)
 	}
 
 	if *s.password == "" {
-		fmt.Printf("INPUT: --  %s  --\nPlease, enter the secret text to store:\n", *s.key)
+		forj_app.output.printf("INPUT: --  %s  --\nPlease, enter the secret text to store:\n", *s.key)
 		if v, err := terminal.ReadPassword(int(os.Stdout.Fd())); err != nil {
-			gotrace.Error("Password read issue. %s", err)
-			return
+			return fmt.Errorf("Password read issue. %s", err)
 		} else {
 			*s.password = string(v)
-			fmt.Println()
+			forj_app.output.println()
 		}
 	}
 
@@ -87,9 +85,12 @@ func (s *secretsSet) doSet() {
 	}
 	if !forj_app.s.SetObjectValue(env, "forjj", keyPath[0], keyPath[1], keyPath[2], &v) {
 		gotrace.Info("'%s' secret text not updated.", *s.key)
-		return
+		return nil
 	}
 
-	forj_app.s.SaveEnv(env)
+	if err := forj_app.s.SaveEnv(env); err != nil {
+		return fmt.Errorf("Unable to save the '%s' deployment environment secrets. %s", env, err)
+	}
 	gotrace.Info("'%s' secret text saved in '%s' deployment environment.", *s.key, env)
+	return nil
 }
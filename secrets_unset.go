@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"forjj/creds"
 	"forjj/scandrivers"
 	"strings"
@@ -27,7 +28,7 @@ func (s *secretsUnset) init(parent *kingpin.CmdClause, common *secretsCommon) {
 
 // doSet register a password to the path given.
 // Only supported path are recognized.
-func (s *secretsUnset) doUnset() {
+func (s *secretsUnset) doUnset() error {
 	ffd := forj_app.f.InMemForjfile()
 
 	scan := scandrivers.NewScanDrivers(ffd, forj_app.drivers)
@@ -53,8 +54,7 @@ func (s *secretsUnset) doUnset() {
 	scan.DoScanDriversObject()
 
 	if _, found := s.elements[*s.key]; !found {
-		gotrace.Error("'%s' is not a valid secret path. check with `forjj secrets`", *s.key)
-		return
+		return fmt.Errorf("'%s' is not a valid secret path. check with `forjj secrets`", *s.key)
 	}
 
 	keyPath := strings.Split(*s.key, "/")
@@ -65,9 +65,12 @@ func (s *secretsUnset) doUnset() {
 	}
 	if !forj_app.s.UnsetObjectValue(env, "forjj", keyPath[0], keyPath[1], keyPath[2]) {
 		gotrace.Info("'%s' secret text not updated.", *s.key)
-		return
+		return nil
 	}
 
-	forj_app.s.SaveEnv(env)
+	if err := forj_app.s.SaveEnv(env); err != nil {
+		return fmt.Errorf("Unable to save the '%s' deployment environment secrets. %s", env, err)
+	}
 	gotrace.Info("'%s' secret text removed from '%s' deployment environment.", *s.key, env)
+	return nil
 }
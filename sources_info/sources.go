@@ -1,36 +1,135 @@
 package sourcesinfo
 
+// Source is one change of a key value.
+type Source struct {
+	Source string `json:"source,omitempty"` // Who set the value. Ex: forjj, flow, cli...
+	Value  string `json:"value"`            // Value set. Empty if the value was removed.
+	File   string `json:"file,omitempty"`   // File which defines the value, if known.
+	Line   int    `json:"line,omitempty"`   // Line in File, if known.
+}
+
+// Sources records who set each key and the history of changes.
 type Sources struct {
-	keys map[string]string
+	keys    map[string]string
+	history map[string][]Source
 }
 
 func newSources() (ret *Sources) {
 	ret = new(Sources)
 	ret.keys = make(map[string]string)
+	ret.history = make(map[string][]Source)
 	return
 }
 
-func (s *Sources)Set(source, key, value string) (ret *Sources) {
+// Set records the source of a key value. An empty value removes the current source, but stays in the history.
+func (s *Sources) Set(source, key, value string) (ret *Sources) {
+	return s.SetFrom(key, Source{Source: source, Value: value})
+}
+
+// SetFrom records a key value change.
+//
+// A change identical to the last one recorded, from the same file, is not added to the history.
+func (s *Sources) SetFrom(key string, from Source) (ret *Sources) {
 	if s == nil {
 		ret = newSources()
 	} else {
 		ret = s
 	}
 
-	if value == "" {
+	if from.Value == "" {
 		delete(ret.keys, key)
 	} else {
-		ret.keys[key] = source
+		ret.keys[key] = from.Source
+	}
+
+	history := ret.history[key]
+	if n := len(history); n > 0 {
+		last := history[n-1]
+		if last.Source == from.Source && last.Value == from.Value && (from.File == "" || from.File == last.File) {
+			return
+		}
 	}
+	ret.history[key] = append(history, from)
 	return
 }
 
-func (s *Sources)Get(key string) (source string) {
+// SetLocation defines the file and line of the last change of a key.
+func (s *Sources) SetLocation(key, file string, line int) {
+	if s == nil {
+		return
+	}
+	if history := s.history[key]; len(history) > 0 {
+		history[len(history)-1].File = file
+		history[len(history)-1].Line = line
+	}
+}
+
+// Get returns the source of the current key value.
+func (s *Sources) Get(key string) (source string) {
 	if s == nil {
 		return
 	}
-	if v, found := s.keys[key] ; found {
+	if v, found := s.keys[key]; found {
 		return v
 	}
 	return
-}
\ No newline at end of file
+}
+
+// History returns the ordered list of changes of a key. The last one is the current one.
+func (s *Sources) History(key string) (ret []Source) {
+	if s == nil {
+		return
+	}
+	history := s.history[key]
+	if len(history) == 0 {
+		return
+	}
+	ret = make([]Source, len(history))
+	copy(ret, history)
+	return
+}
+
+// Keys returns the list of keys having a history.
+func (s *Sources) Keys() (ret []string) {
+	if s == nil {
+		return
+	}
+	ret = make([]string, 0, len(s.history))
+	for key := range s.history {
+		ret = append(ret, key)
+	}
+	return
+}
+
+// Merge adds the history of a key from another Sources, before recording its current value.
+//
+// It is used when an object is merged from another one, to keep the whole override chain.
+func (s *Sources) Merge(key string, from *Sources) (ret *Sources) {
+	if s == nil {
+		ret = newSources()
+	} else {
+		ret = s
+	}
+	if from == nil || from == ret {
+		return
+	}
+	for _, change := range from.history[key] {
+		ret = ret.SetFrom(key, change)
+	}
+	return
+}
+
+// Copy returns a copy of the sources, which can be updated without updating the original one.
+func (s *Sources) Copy() (ret *Sources) {
+	if s == nil {
+		return
+	}
+	ret = newSources()
+	for key, source := range s.keys {
+		ret.keys[key] = source
+	}
+	for key, history := range s.history {
+		ret.history[key] = append([]Source(nil), history...)
+	}
+	return
+}
@@ -115,3 +115,81 @@ func TestGet(t *testing.T) {
 		t.Errorf("Expect get to return ''. Got '%s'", ret3)
 	}
 }
+
+func TestHistory(t *testing.T) {
+	t.Log("Expect set to keep the history of changes.")
+
+	var sources *Sources
+
+	const (
+		src1   = "src1"
+		src2   = "src2"
+		key1   = "key1"
+		value1 = "value1"
+		value2 = "value2"
+		file1  = "Forjfile"
+	)
+	// ------------ Run function to test
+	// sources is nil
+	ret := sources.History(key1)
+
+	// ------------ Test result
+	if ret != nil {
+		t.Errorf("Expect history to return nil. Got '%d' changes", len(ret))
+	}
+
+	// ------------ Run function to test
+	sources = sources.SetFrom(key1, Source{Source: src1, Value: value1, File: file1, Line: 3})
+	sources = sources.Set(src1, key1, value1) // Same change: not recorded.
+	sources = sources.Set(src2, key1, value2)
+	sources = sources.Set(src2, key1, "")
+	ret = sources.History(key1)
+
+	// ------------ Test result
+	if len(ret) != 3 {
+		t.Errorf("Expect history to have 3 changes. Got '%d'", len(ret))
+	} else if ret[0].Source != src1 || ret[0].File != file1 || ret[0].Line != 3 {
+		t.Errorf("Expect first change from '%s' in '%s'. Got '%s' in '%s'", src1, file1, ret[0].Source, ret[0].File)
+	} else if ret[1].Source != src2 || ret[1].Value != value2 {
+		t.Errorf("Expect second change to be '%s' from '%s'. Got '%s' from '%s'", value2, src2, ret[1].Value, ret[1].Source)
+	} else if ret[2].Value != "" {
+		t.Errorf("Expect last change to remove the value. Got '%s'", ret[2].Value)
+	} else if v := sources.Get(key1); v != "" {
+		t.Errorf("Expect get to return ''. Got '%s'", v)
+	}
+
+	// ------------ update context
+	sources.SetLocation(key1, file1, 10)
+	// ------------ Test result
+	if ret = sources.History(key1); ret[2].File != file1 || ret[2].Line != 10 {
+		t.Errorf("Expect set location to update the last change. Got '%s:%d'", ret[2].File, ret[2].Line)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	t.Log("Expect merge to add the history of another sources.")
+
+	var sources, from *Sources
+
+	const (
+		src1   = "src1"
+		src2   = "src2"
+		key1   = "key1"
+		value1 = "value1"
+		value2 = "value2"
+	)
+	// ------------ Run function to test
+	sources = sources.Set(src1, key1, value1)
+	from = from.Set(src2, key1, value2)
+	sources = sources.Merge(key1, from)
+	sources = sources.Set(src2, key1, value2) // Merged value set. Already in the history.
+
+	// ------------ Test result
+	if ret := sources.History(key1); len(ret) != 2 {
+		t.Errorf("Expect history to have 2 changes. Got '%d'", len(ret))
+	} else if ret[0].Value != value1 || ret[1].Value != value2 {
+		t.Errorf("Expect history to be '%s' then '%s'. Got '%s' then '%s'", value1, value2, ret[0].Value, ret[1].Value)
+	} else if v := sources.Get(key1); v != src2 {
+		t.Errorf("Expect get to return '%s'. Got '%s'", src2, v)
+	}
+}
@@ -3,54 +3,52 @@ package main
 import (
 	"fmt"
 	"forjj/creds"
+	"forjj/forjfile"
 	"log"
 	"regexp"
 
 	"github.com/forj-oss/forjj-modules/trace"
 )
 
-func (a *Forj) updateAction(string) {
-	if err := a.Update(); err != nil {
-		log.Fatalf("Forjj update issue. %s", err)
+func (a *Forj) updateAction(string) error {
+	var err error
+	if a.deployments != nil {
+		err = a.runOnDeployments()
+	} else {
+		err = a.Update()
+	}
+	if err != nil {
+		return fmt.Errorf("Forjj update issue. %s", err)
 	}
 	println("FORJJ - update ", a.w.GetString("organization"), " DONE") // , cmd.ProcessState.Sys().WaitStatus)
-
+	return nil
 }
 
-// Execute an update on the workspace given.
+// prepareFlows prepares the in memory Forjfile and the flows to apply on it, as `forjj update` does.
 //
-// Workspace data has been initialized or loaded.
-// forjj-options has been initialized or loaded
-func (a *Forj) Update() error {
-	if _, err := a.w.EnsureExist(); err != nil {
-		return fmt.Errorf("Invalid workspace. %s. Please create it with 'forjj create'", err)
-	}
-
-	defer func() {
-		// save infra repository location in the workspace.
-		a.w.Save()
-	}()
-
+// The Forjfile is validated, plugin defaults are set and the deployment Forjfile is merged. Missing deployment
+// repositories are added, with a warning if warn is true. It returns the in memory Forjfile.
+func (a *Forj) prepareFlows(warn bool) (*forjfile.DeployForgeYaml, error) {
 	if err := a.ValidateForjfile(); err != nil {
-		return fmt.Errorf("Your Forjfile is having issues. %s Try to fix and retry", err)
+		return nil, fmt.Errorf("Your Forjfile is having issues. %s Try to fix and retry", err)
 	}
 
 	// Set plugin defaults for objects defined by plugins loaded.
 	if err := a.scanAndSetDefaults(a.f.DeployForjfile(), creds.Global); err != nil {
-		return fmt.Errorf("Unable to update. Global dispatch issue. %s", err)
+		return nil, fmt.Errorf("Unable to update. Global dispatch issue. %s", err)
 	}
 
 	// Build in memory representation from source files loaded.
 	if err := a.f.BuildForjfileInMem(); err != nil {
-		return err
+		return nil, err
 	}
 
 	// Get it
 	ffd := a.f.InMemForjfile()
 
 	// Add missing deployment Repositories and warn
-	if err := a.DefineDeployRepositories(ffd, true); err != nil {
-		return fmt.Errorf("Issues to automatically add your deployment repositories. %s", err)
+	if err := a.DefineDeployRepositories(ffd, warn); err != nil {
+		return nil, fmt.Errorf("Issues to automatically add your deployment repositories. %s", err)
 	}
 
 	// Defining information about current deployment repository
@@ -58,14 +56,35 @@ func (a *Forj) Update() error {
 
 	// Load flow identified by Forjfile source with missing repos.
 	if err := a.FlowInit(); err != nil {
-		return err
+		return nil, err
 	}
 
 	if err := a.define_infra_upstream(); err != nil {
-		return fmt.Errorf("Unable to identify a valid infra repository upstream. %s", err)
+		return nil, fmt.Errorf("Unable to identify a valid infra repository upstream. %s", err)
 	}
 
 	gotrace.Trace("Infra upstream selected: '%s'", a.w.GetString("infra-instance-name"))
+	return ffd, nil
+}
+
+// Execute an update on the workspace given.
+//
+// Workspace data has been initialized or loaded.
+// forjj-options has been initialized or loaded
+func (a *Forj) Update() error {
+	if _, err := a.w.EnsureExist(); err != nil {
+		return fmt.Errorf("Invalid workspace. %s. Please create it with 'forjj create'", err)
+	}
+
+	defer func() {
+		// save infra repository location in the workspace.
+		a.w.Save()
+	}()
+
+	ffd, err := a.prepareFlows(true)
+	if err != nil {
+		return err
+	}
 
 	// Apply the flow to the inMemForjfile
 	if err := a.FlowApply(); err != nil {
@@ -90,37 +109,49 @@ func (a *Forj) Update() error {
 	//    return fmt.Errorf("Unable to move to your feature branch. %s", err)
 	//}
 
-	instances := a.define_drivers_execution_order()
+	// Record infra and deploy repositories starting points, to restore them if a driver fails.
+	transaction, err := a.beginTransaction()
+	if err != nil {
+		return err
+	}
 
-	// Loop on drivers requested like github or jenkins
-	for _, instance := range instances {
+	// Run drivers requested like github or jenkins, following their dependencies.
+	if err := a.do_drivers_run(func(instance string) error {
 		d := a.drivers[instance]
 		if err, aborted := a.do_driver_task("update", instance); err != nil {
 			if !aborted {
 				return fmt.Errorf("Failed to update '%s' source files. %s", instance, err)
 			}
 			log.Printf("Warning. %s", err)
-			continue
+			return nil
 		}
 
 		if d.HasNoFiles() {
 			gotrace.Info("No files to add/commit.")
-			continue
+			return nil
 		}
 
 		// Committing source code.
 		if err := a.do_driver_add(d); err != nil {
 			return fmt.Errorf("Failed to Add '%s' source files. %s", instance, err)
 		}
+		return nil
+	}); err != nil {
+		return transaction.rollback(err)
 	}
 
 	commitMsg := fmt.Sprintf("Forge '%s' updated.", a.w.GetString("organization"))
 
-	if deployPublish, found, _ := a.cli.GetBoolValue("_app", "forjj", "deploy-publish"); found && deployPublish {
+	deployPublish, found, _ := a.cli.GetBoolValue("_app", "forjj", "deploy-publish")
+	deployPublish = found && deployPublish
+	if deployPublish {
 		if err := a.d.GitCommit(commitMsg); err != nil {
-			return fmt.Errorf("Failed to commit deploy files. %s", err)
+			return transaction.rollback(fmt.Errorf("Failed to commit deploy files. %s", err))
 		}
+	}
+	transaction.commit()
 
+	if deployPublish {
 		if err := a.d.GitPush(false); err != nil {
 			return fmt.Errorf("Failed to push deploy commits. %s", err)
 		}
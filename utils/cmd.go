@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"io"
 	"log"
 	"os"
 	"os/exec"
@@ -12,10 +13,18 @@ import (
 	"bufio"
 )
 
+// cmdOutput is where commands output is displayed. See SetCmdOutput.
+var cmdOutput io.Writer = os.Stdout
+
+// SetCmdOutput defines where RunCmd and RunCmdOutput display the commands output. Default is the standard output.
+func SetCmdOutput(w io.Writer) {
+	cmdOutput = w
+}
+
 // Simple function to call a shell command and display to stdout
 // stdout is displayed as is when it arrives, while stderr is displayed in Red, line per line.
 func RunCmd(command string, args ...string) int {
-	logger := log.New(os.Stdout, "", log.LstdFlags)
+	logger := log.New(cmdOutput, "", log.LstdFlags)
 	// Setup a streamer that we'll pipe cmd.Stdout to
 	logStreamerOut := logstreamer.NewLogstreamer(logger, "stdout", false)
 	defer logStreamerOut.Close()
@@ -53,7 +62,7 @@ func RunCmd(command string, args ...string) int {
 // RunCmdOutput run a command and return the standard output as result. 
 // stderr is displayed in Red, line per line.
 func RunCmdOutput(command string, args ...string) (string, int) {
-	logger := log.New(os.Stdout, "", log.LstdFlags)
+	logger := log.New(cmdOutput, "", log.LstdFlags)
 	// Setup a streamer that we'll pipe cmd.Stderr to.
 	// We want to record/buffer anything that's written to this (3rd argument true)
 	logStreamerErr := logstreamer.NewLogstreamer(logger, "stderr", true)
@@ -0,0 +1,54 @@
+package utils
+
+// EditDistance returns the Levenshtein distance between 2 strings.
+func EditDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
+
+// Suggest returns the candidate the closest to word, or "" if none are close enough.
+//
+// A candidate is close enough if at most 1 edit every 3 characters is required (2 minimum).
+func Suggest(word string, candidates ...string) (ret string) {
+	max := len(word) / 3
+	if max < 2 {
+		max = 2
+	}
+	best := max + 1
+	for _, candidate := range candidates {
+		if candidate == word {
+			continue
+		}
+		if distance := EditDistance(word, candidate); distance < best || (distance == best && candidate < ret) {
+			best = distance
+			ret = candidate
+		}
+	}
+	return
+}
+
+func minInt(values ...int) (ret int) {
+	ret = values[0]
+	for _, v := range values[1:] {
+		if v < ret {
+			ret = v
+		}
+	}
+	return
+}
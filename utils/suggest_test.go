@@ -0,0 +1,37 @@
+package utils
+
+import "testing"
+
+func TestEditDistance(t *testing.T) {
+	t.Log("Expecting EditDistance to count insertions, deletions and substitutions.")
+	for _, test := range []struct {
+		a, b     string
+		distance int
+	}{
+		{"", "", 0},
+		{"flow", "flow", 0},
+		{"flow", "flows", 1},
+		{"in-relation-with", "in-relations-with", 1},
+		{"title", "tilte", 2},
+		{"kitten", "sitting", 3},
+	} {
+		if v := EditDistance(test.a, test.b); v != test.distance {
+			t.Errorf("Expected EditDistance('%s', '%s') to be %d. Got %d", test.a, test.b, test.distance, v)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	t.Log("Expecting Suggest to return the closest candidate.")
+	candidates := []string{"title", "flow", "in-relation-with", "repo-template"}
+
+	if v := Suggest("in-relations-with", candidates...); v != "in-relation-with" {
+		t.Errorf("Expected '%s'. Got '%s'", "in-relation-with", v)
+	}
+	if v := Suggest("tilte", candidates...); v != "title" {
+		t.Errorf("Expected '%s'. Got '%s'", "title", v)
+	}
+	if v := Suggest("something-else", candidates...); v != "" {
+		t.Errorf("Expected no suggestion. Got '%s'", v)
+	}
+}
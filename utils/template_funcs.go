@@ -0,0 +1,206 @@
+package utils
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// TemplateFuncs returns the functions shared by forjj templates: flows, plugins parameters and plugin
+// extensions.
+//
+// As for text/template builtins, the value to transform is the last parameter, so functions can be piped.
+// Ex: {{ .Name | replace "_" "-" | upper }}
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		// Strings
+		"lower":      strings.ToLower,
+		"upper":      strings.ToUpper,
+		"title":      strings.Title,
+		"trim":       strings.TrimSpace,
+		"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+		"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+		"replace":    func(old, new, s string) string { return strings.Replace(s, old, new, -1) },
+		"split":      func(sep, s string) []string { return strings.Split(s, sep) },
+		"join":       templateJoin,
+		"contains":   func(substr, s string) bool { return strings.Contains(s, substr) },
+		"hasPrefix":  func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+		"hasSuffix":  func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+		// Defaults
+		"default":  templateDefault,
+		"coalesce": templateCoalesce,
+		"empty":    templateEmpty,
+		// Regular expressions
+		"regexMatch":   templateRegexMatch,
+		"regexFind":    templateRegexFind,
+		"regexReplace": templateRegexReplace,
+		// Lists and maps
+		"list":  func(items ...interface{}) []interface{} { return items },
+		"first": templateFirst,
+		"last":  templateLast,
+		"has":   templateHas,
+		"dict":  templateDict,
+		"keys":  templateKeys,
+		// Encoding and hashing
+		"b64enc": func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) },
+		"b64dec": templateB64Dec,
+		"md5":    func(s string) string { sum := md5.Sum([]byte(s)); return hex.EncodeToString(sum[:]) },
+		"sha1":   func(s string) string { sum := sha1.Sum([]byte(s)); return hex.EncodeToString(sum[:]) },
+		"sha256": func(s string) string { sum := sha256.Sum256([]byte(s)); return hex.EncodeToString(sum[:]) },
+		// Kept for compatibility.
+		"concatenate": fmt.Sprint,
+		"ToLower":     strings.ToLower,
+	}
+}
+
+// templateJoin joins list items as strings.
+func templateJoin(sep string, list interface{}) (string, error) {
+	items, err := templateItems(list)
+	if err != nil {
+		return "", err
+	}
+	values := make([]string, len(items))
+	for index, item := range items {
+		values[index] = fmt.Sprint(item)
+	}
+	return strings.Join(values, sep), nil
+}
+
+// templateDefault returns value, or def if value is empty.
+func templateDefault(def interface{}, value ...interface{}) interface{} {
+	if len(value) == 0 || templateEmpty(value[0]) {
+		return def
+	}
+	return value[0]
+}
+
+// templateCoalesce returns the first value which is not empty.
+func templateCoalesce(values ...interface{}) interface{} {
+	for _, value := range values {
+		if !templateEmpty(value) {
+			return value
+		}
+	}
+	return nil
+}
+
+// templateEmpty returns true if value is nil, false, 0, "" or an empty list or map.
+func templateEmpty(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Array, reflect.Slice, reflect.Map, reflect.String:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return reflect.DeepEqual(value, reflect.Zero(v.Type()).Interface())
+}
+
+func templateRegexMatch(expr, s string) (bool, error) {
+	return regexp.MatchString(expr, s)
+}
+
+func templateRegexFind(expr, s string) (string, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return "", err
+	}
+	return re.FindString(s), nil
+}
+
+// templateRegexReplace replaces matches of expr. repl can use $1 for submatches.
+func templateRegexReplace(expr, repl, s string) (string, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return "", err
+	}
+	return re.ReplaceAllString(s, repl), nil
+}
+
+func templateFirst(list interface{}) (interface{}, error) {
+	items, err := templateItems(list)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return items[0], nil
+}
+
+func templateLast(list interface{}) (interface{}, error) {
+	items, err := templateItems(list)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return items[len(items)-1], nil
+}
+
+// templateHas returns true if the list has the item.
+func templateHas(item, list interface{}) (bool, error) {
+	items, err := templateItems(list)
+	if err != nil {
+		return false, err
+	}
+	for _, v := range items {
+		if reflect.DeepEqual(v, item) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// templateDict returns a map from key/value pairs.
+func templateDict(pairs ...interface{}) (map[string]interface{}, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("dict requires key/value pairs")
+	}
+	ret := make(map[string]interface{})
+	for index := 0; index < len(pairs); index += 2 {
+		ret[fmt.Sprint(pairs[index])] = pairs[index+1]
+	}
+	return ret, nil
+}
+
+// templateKeys returns map keys in sorted order.
+func templateKeys(m interface{}) ([]string, error) {
+	v := reflect.ValueOf(m)
+	if v.Kind() != reflect.Map {
+		return nil, fmt.Errorf("keys requires a map, not %T", m)
+	}
+	ret := make([]string, 0, v.Len())
+	for _, key := range v.MapKeys() {
+		ret = append(ret, fmt.Sprint(key.Interface()))
+	}
+	sort.Strings(ret)
+	return ret, nil
+}
+
+func templateB64Dec(s string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(s)
+	return string(data), err
+}
+
+// templateItems returns items of a slice or an array.
+func templateItems(list interface{}) ([]interface{}, error) {
+	if list == nil {
+		return nil, nil
+	}
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("a list is required, not %T", list)
+	}
+	ret := make([]interface{}, v.Len())
+	for index := range ret {
+		ret[index] = v.Index(index).Interface()
+	}
+	return ret, nil
+}
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"testing"
+	"text/template"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	t.Log("Expecting TemplateFuncs to be usable in templates and pipelines.")
+	data := map[string]interface{}{
+		"Name":  "my_repo",
+		"Empty": "",
+		"Roles": []string{"code", "infra"},
+	}
+	for _, test := range []struct {
+		template, expected string
+	}{
+		{`{{ .Name | upper }}`, "MY_REPO"},
+		{`{{ .Name | replace "_" "-" | title }}`, "My-Repo"},
+		{`{{ trimPrefix "my_" .Name }}`, "repo"},
+		{`{{ split "_" .Name | join "," }}`, "my,repo"},
+		{`{{ .Empty | default "none" }}`, "none"},
+		{`{{ coalesce .Empty .Name }}`, "my_repo"},
+		{`{{ regexReplace "^my_(.*)$" "${1}-ci" .Name }}`, "repo-ci"},
+		{`{{ regexMatch "^my" .Name }}`, "true"},
+		{`{{ has "infra" .Roles }} {{ last .Roles }}`, "true infra"},
+		{`{{ keys (dict "b" 1 "a" 2) | join " " }}`, "a b"},
+		{`{{ b64enc "forjj" }} {{ b64enc "forjj" | b64dec }}`, "Zm9yamo= forjj"},
+		{`{{ sha1 "forjj" }}`, "d944d59d3af2c3ed360de91635ddccbe8d29e268"},
+		{`{{ concatenate "a" "b" | ToLower }}`, "ab"},
+	} {
+		tmpl := template.New("test")
+		if v, err := Evaluate(test.template, tmpl, data, TemplateFuncs()); err != nil {
+			t.Errorf("Expected '%s' to be evaluated. Got error %s", test.template, err)
+		} else if v != test.expected {
+			t.Errorf("Expected '%s' to be evaluated to '%s'. Got '%s'", test.template, test.expected, v)
+		}
+	}
+}
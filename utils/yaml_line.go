@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"gopkg.in/yaml.v3"
+)
+
+// YamlLine returns the line of the mapping entry found at the keys path, in a YAML node.
+//
+// If a key is not found, it returns the line of the last entry found, or 0 if none are found.
+// Ex: YamlLine(node, "repositories", "myrepo", "title")
+func YamlLine(node *yaml.Node, keys ...string) (line int) {
+	for _, key := range keys {
+		node = yamlMapping(node)
+		if node == nil {
+			return
+		}
+		var value *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				line = node.Content[i].Line
+				value = node.Content[i+1]
+				break
+			}
+		}
+		if value == nil {
+			return
+		}
+		node = value
+	}
+	return
+}
+
+// yamlMapping returns the mapping node of a document or an alias. nil if the node is not a mapping.
+func yamlMapping(node *yaml.Node) *yaml.Node {
+	for node != nil {
+		switch node.Kind {
+		case yaml.DocumentNode:
+			if len(node.Content) == 0 {
+				return nil
+			}
+			node = node.Content[0]
+		case yaml.AliasNode:
+			node = node.Alias
+		case yaml.MappingNode:
+			return node
+		default:
+			return nil
+		}
+	}
+	return nil
+}
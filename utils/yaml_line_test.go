@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestYamlLine(t *testing.T) {
+	t.Log("Expecting YamlLine to return the line of the entry found.")
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(`# Comment
+repositories:
+  myrepo: &repo
+    title: My repo
+
+  other: *repo
+`), &doc); err != nil {
+		t.Fatalf("Unable to parse the document. %s", err)
+	}
+
+	for _, test := range []struct {
+		keys []string
+		line int
+	}{
+		{[]string{"repositories"}, 2},
+		{[]string{"repositories", "myrepo", "title"}, 4},
+		{[]string{"repositories", "myrepo", "flow"}, 3},
+		{[]string{"repositories", "other", "title"}, 4},
+		{[]string{"applications", "myapp"}, 0},
+		{[]string{"repositories", "myrepo", "title", "more"}, 4},
+	} {
+		if v := YamlLine(&doc, test.keys...); v != test.line {
+			t.Errorf("Expected line %d for %v. Got %d", test.line, test.keys, v)
+		}
+	}
+}
@@ -2,15 +2,20 @@ package main
 
 import (
 	"fmt"
-	"log"
+	"forjj/drivers"
+	"forjj/forjfile"
+	"forjj/utils"
+	"sort"
 
+	"github.com/forj-oss/forjj-modules/trace"
 	"github.com/forj-oss/goforjj"
 )
 
-func (a *Forj) validateAction(string) {
+func (a *Forj) validateAction(string) error {
 	if err := a.Validate(); err != nil {
-		log.Fatalf("Forjj validate issue. %s", err)
+		return fmt.Errorf("Forjj validate issue. %s", err)
 	}
+	return nil
 }
 
 // Validate check forjfile rules and return an error is the Forjfile loaded is respecting those rules.
@@ -42,24 +47,184 @@ func (a *Forj) FoundValidAppFlag(key, driver, object string, required bool) (_ b
 }
 
 // ValidateForjfile read all object fields and check if they are recognized by forjj or plugins.
+//
+// With --all-deployments, each deployment Forjfile merged with the master one is validated as well.
+// All errors and warnings found are reported at once.
 func (a *Forj) ValidateForjfile() (_ error) {
-	f := a.f.DeployForjfile()
+	report := forjfile.NewValidationReport()
 
-	if err := a.f.Validate(); err != nil {
+	a.f.SetFlowLoader(a.flows.Check)
+	a.f.ValidateTo(report)
+	a.validateKeys(a.f.DeployForjfile(), report)
+
+	if all, _, _ := a.cli.GetBoolValue("_app", "forjj", validateAllDeploymentsF); all {
+		deploys := make([]string, 0, len(a.f.GetDeployments()))
+		for name := range a.f.GetDeployments() {
+			deploys = append(deploys, name)
+		}
+		sort.Strings(deploys)
+		for _, name := range deploys {
+			if ffd := a.f.ValidateDeployment(name, report); ffd != nil {
+				report.SetDeployment(name)
+				a.validateKeys(ffd, report)
+				report.SetDeployment("")
+			}
+		}
+	}
+
+	a.output.setData(report.Issues)
+	for _, warning := range report.Warnings() {
+		gotrace.Warning("%s", warning)
+	}
+	if err := report.Err(); err != nil {
 		return fmt.Errorf("Validation error. %s", err)
 	}
 
-	// AppYamlStruct.More
-	for _, app := range f.Apps {
-		for key := range app.More {
-			if found, err := a.FoundValidAppFlag(key, app.Driver, goforjj.ObjectApp, true); err != nil {
-				return err
+	a.output.printf("Validated successfully.\n")
+	return
+}
+
+// validateKeys reports object keys which are not recognized by forjj or by the plugins which define the object.
+// A close known key is suggested.
+func (a *Forj) validateKeys(ffd *forjfile.DeployForgeYaml, report *forjfile.ValidationReport) {
+	// AppYamlStruct.More: defined by the application driver.
+	for _, name := range sortedNames(ffd.GetInstances("app")) {
+		app := ffd.Apps[name]
+		// Applications of other deployments may not have been loaded from the current deployment.
+		if err := a.loadAppDriver(app); err != nil {
+			report.AddError("app/"+name, "%s", err)
+			continue
+		}
+		for _, key := range sortedKeys(app.More) {
+			if found, err := a.FoundValidAppFlag(key, name, goforjj.ObjectApp, true); err != nil {
+				report.AddError("app/"+name, "%s", err)
+				break
 			} else if !found {
-				return fmt.Errorf("'%s' has no effect. No drivers use it", key)
+				candidates := append(forjfile.CoreKeys(goforjj.ObjectApp), pluginObjectKeys(a.drivers[name], goforjj.ObjectApp)...)
+				report.AddError("app/"+name+"/"+key, "'%s' has no effect. No drivers use it.%s", key, didYouMean(key, candidates))
 			}
 		}
 	}
 
-	fmt.Print("Validated successfully.\n")
+	// RepoStruct.More, UserStruct.More and GroupStruct.More
+	for _, name := range sortedNames(ffd.GetInstances("repo")) {
+		a.validateObjectKeys(report, "repo", name, sortedKeys(ffd.Repos[name].More))
+	}
+	for _, name := range sortedNames(ffd.GetInstances("user")) {
+		a.validateObjectKeys(report, "user", name, sortedKeys(ffd.Users[name].More))
+	}
+	for _, name := range sortedNames(ffd.GetInstances("group")) {
+		a.validateObjectKeys(report, "group", name, sortedKeys(ffd.Groups[name].More))
+	}
+
+	// DeployForgeYaml.More: plugins objects.
+	objects := make([]string, 0, len(ffd.More))
+	for object := range ffd.More {
+		objects = append(objects, object)
+	}
+	for _, object := range sortedNames(objects) {
+		for _, instance := range sortedNames(ffd.GetInstances(object)) {
+			a.validateObjectKeys(report, object, instance, sortedNames(ffd.GetKeys(object, instance)))
+		}
+	}
+}
+
+// validateObjectKeys reports keys of an object instance which are not defined by any plugin defining the object.
+func (a *Forj) validateObjectKeys(report *forjfile.ValidationReport, object, instance string, keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+	objects := []goforjj.YamlObject{}
+	candidates := forjfile.CoreKeys(object)
+	pluginsObjects := []string{}
+	for _, d := range a.drivers {
+		if d.Plugin == nil {
+			continue
+		}
+		for name := range d.Plugin.Yaml.Objects {
+			pluginsObjects = append(pluginsObjects, name)
+		}
+		if o, found := d.Plugin.Yaml.Objects[object]; found {
+			objects = append(objects, o)
+			candidates = append(candidates, pluginObjectKeys(d, object)...)
+		}
+	}
+
+	path := object + "/" + instance
+	if len(objects) == 0 && forjfile.CoreKeys(object) == nil {
+		report.AddError(path, "No drivers define '%s' objects.%s", object, didYouMean(object, pluginsObjects))
+		return
+	}
+	for _, key := range keys {
+		found := inList(key, candidates)
+		for index := 0; !found && index < len(objects); index++ {
+			found = objects[index].HasValidKey(key)
+		}
+		if !found {
+			report.AddError(path+"/"+key, "'%s' has no effect. No drivers use it.%s", key, didYouMean(key, candidates))
+		}
+	}
+}
+
+// pluginObjectKeys returns keys defined by the driver plugin for the object, including group keys. (<group>-<flag>)
+func pluginObjectKeys(d *drivers.Driver, object string) (keys []string) {
+	if d == nil || d.Plugin == nil {
+		return
+	}
+	o, found := d.Plugin.Yaml.Objects[object]
+	if !found {
+		return
+	}
+	for flag := range o.Flags {
+		keys = append(keys, flag)
+	}
+	for group, groupDef := range o.Groups {
+		for flag := range groupDef.Flags {
+			keys = append(keys, group+"-"+flag)
+		}
+	}
 	return
 }
+
+// didYouMean returns a suggestion to add to an error message, if a candidate is close to the word.
+func didYouMean(word string, candidates []string) string {
+	if v := utils.Suggest(word, candidates...); v != "" {
+		return fmt.Sprintf(" Did you mean '%s'?", v)
+	}
+	return ""
+}
+
+func inList(word string, list []string) bool {
+	for _, v := range list {
+		if v == word {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	return sortedNames(keys)
+}
+
+func sortedNames(names []string) []string {
+	sort.Strings(names)
+	return names
+}
+
+// loadAppDriver loads the driver definition of an application, if not already loaded.
+// Applications of other deployments may not have been loaded from the current deployment.
+func (a *Forj) loadAppDriver(app *forjfile.AppStruct) error {
+	if _, found := a.drivers[app.Name()]; found {
+		return nil
+	}
+	a.add_defined_driver(app)
+	if err := a.load_driver_options(app.Name()); err != nil {
+		return fmt.Errorf("Unable to load plugin information for instance '%s'. %s", app.Name(), err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"forjj/drivers"
+	"forjj/forjfile"
+	"testing"
+
+	"github.com/forj-oss/goforjj"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateObjectKeys(t *testing.T) {
+	assert := assert.New(t)
+
+	a := Forj{drivers: make(map[string]*drivers.Driver)}
+	a.drivers["github"] = &drivers.Driver{
+		Name:   "github",
+		Plugin: &goforjj.Driver{Yaml: goforjj.YamlPlugin{Objects: map[string]goforjj.YamlObject{"app": {}}}},
+	}
+
+	/*************************************/
+	testCase := "when a repo uses only core keys and no drivers define repo objects"
+
+	report := forjfile.NewValidationReport()
+	a.validateObjectKeys(report, "repo", "myrepo", []string{"title", "upstream-app", "flow"})
+	assert.Emptyf(report.Errors(), "Expect no errors %s", testCase)
+
+	/*************************************/
+	testCase = "when a repo uses an unknown key and no drivers define repo objects"
+
+	report = forjfile.NewValidationReport()
+	a.validateObjectKeys(report, "repo", "myrepo", []string{"title", "titel"})
+	if errors := report.Errors(); assert.Lenf(errors, 1, "Expect 1 error %s", testCase) {
+		assert.Equalf("repo/myrepo/titel", errors[0].Path, "Expect the unknown key to be reported %s", testCase)
+		assert.Containsf(errors[0].Message, "Did you mean 'title'?", "Expect a suggestion %s", testCase)
+	}
+
+	/*************************************/
+	testCase = "when an object is not defined by any driver nor by forjj"
+
+	report = forjfile.NewValidationReport()
+	a.validateObjectKeys(report, "project", "myproject", []string{"title"})
+	if errors := report.Errors(); assert.Lenf(errors, 1, "Expect 1 error %s", testCase) {
+		assert.Equalf("project/myproject", errors[0].Path, "Expect the object to be reported %s", testCase)
+	}
+}